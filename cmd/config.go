@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"mycmd/pkg/config"
+	"mycmd/pkg/initialize"
+	"mycmd/pkg/logger"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "查看和管理配置",
+	Long: `配置按以下顺序加载，后面的覆盖前面的：
+默认值 → 配置文件 → 环境变量（如 MYCMD_FLOW_TODO_DIR） → 命令行参数 --set flow.todo_dir=...`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "显示生效的配置以及每个配置项的来源",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger.Info("配置文件: %s", config.File())
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tENV")
+		for _, entry := range config.Entries() {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Key, entry.Value, entry.Source, entry.Env)
+		}
+		return w.Flush()
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "校验配置，列出所有问题以及问题所在的行号",
+	Args:         cobra.NoArgs,
+	Annotations:  map[string]string{skipConfigAnnotation: "true"},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateConfig(configFile, configOverrides); err != nil {
+			return err
+		}
+		logger.Success("配置有效: %s", config.File())
+		return nil
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:         "path",
+	Short:       "显示使用的配置文件路径",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{skipConfigAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := resolveConfigFile()
+		if err != nil {
+			return err
+		}
+		fmt.Println(file)
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "显示配置项生效的值",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := config.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(entry.Value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "修改配置文件中的配置项，保留文件中的注释",
+	Long: `修改配置文件中的配置项，保留文件中的注释和其它配置项。
+列表和映射使用 YAML 格式，如: mycmd config set flow.projects '{work: {BUGFIX: [BCS, DUAL]}}'`,
+	Args:         cobra.ExactArgs(2),
+	Annotations:  map[string]string{skipConfigAnnotation: "true"},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := resolveConfigFile()
+		if err != nil {
+			return err
+		}
+		// 修改后的配置有新的问题时不写入文件
+		err = config.SetFileValue(file, args[0], args[1])
+		if printProblems(err) {
+			return fmt.Errorf("配置没有修改: %s", file)
+		}
+		if err != nil {
+			return err
+		}
+		logger.Success("已修改配置 %s: %s = %s", file, args[0], args[1])
+
+		return validateConfig(file, nil)
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:          "edit",
+	Short:        "使用 $VISUAL 或 $EDITOR 编辑配置文件，编辑后校验配置",
+	Args:         cobra.NoArgs,
+	Annotations:  map[string]string{skipConfigAnnotation: "true"},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := resolveConfigFile()
+		if err != nil {
+			return err
+		}
+
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+
+		// 编辑器可以带参数，如 EDITOR="code --wait"
+		fields := strings.Fields(editor)
+		c := exec.Command(fields[0], append(fields[1:], file)...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("运行编辑器 %s 失败: %w", editor, err)
+		}
+
+		if err := validateConfig(file, nil); err != nil {
+			return err
+		}
+		logger.Success("配置有效: %s", file)
+		return nil
+	},
+}
+
+// resolveConfigFile 返回使用的配置文件的绝对路径
+func resolveConfigFile() (string, error) {
+	file, err := initialize.ResolveConfigFile(configFile)
+	if err != nil {
+		return "", fmt.Errorf("%w，可以使用 mycmd config init 创建配置文件", err)
+	}
+	return filepath.Abs(file)
+}
+
+// validateConfig 加载并校验配置，打印所有问题
+func validateConfig(file string, overrides []string) error {
+	err := initialize.Init(file, overrides)
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		printProblems(err)
+		return fmt.Errorf("配置有 %d 个问题", len(validationErr.Problems))
+	}
+	return err
+}
+
+// printProblems err 为 *config.ValidationError 时打印所有问题并返回 true
+func printProblems(err error) bool {
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+	for _, problem := range validationErr.Problems {
+		logger.Error("%s", problem)
+	}
+	return true
+}
+
+func init() {
+	configCmd.AddCommand(
+		configShowCmd,
+		configValidateCmd,
+		configPathCmd,
+		configGetCmd,
+		configSetCmd,
+		configEditCmd,
+		configInitCmd,
+	)
+}
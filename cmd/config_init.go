@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"mycmd/pkg/config"
+	"mycmd/pkg/fileutil"
+	"mycmd/pkg/initialize"
+	"mycmd/pkg/logger"
+)
+
+type configInitOptions struct {
+	config.InitOptions
+	force bool
+}
+
+var configInitOpts = &configInitOptions{}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "创建带注释的配置文件",
+	Long: `创建带注释的配置文件，默认写入 ~/.config/mycmd/config.yaml，也可以通过 --config 或环境变量 MYCMD_CONFIG 指定。
+没有指定 --todo-dir 时在终端中依次询问各个配置项。`,
+	Args:         cobra.NoArgs,
+	Annotations:  map[string]string{skipConfigAnnotation: "true"},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configInitOpts.run()
+	},
+}
+
+func init() {
+	configInitCmd.Flags().StringVar(&configInitOpts.TodoDir, "todo-dir", "", "todo 文件夹路径")
+	configInitCmd.Flags().StringVar(&configInitOpts.WeekStart, "week-start", "monday", "每周的第一天")
+	configInitCmd.Flags().IntVar(&configInitOpts.CurrentYear, "current-year", 0, "MM/DD 格式日期使用的年份，0 表示使用今年")
+	configInitCmd.Flags().BoolVar(&configInitOpts.force, "force", false, "配置文件已存在时覆盖")
+}
+
+func (o *configInitOptions) run() error {
+	file, err := o.targetFile()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err == nil && !o.force {
+		return fmt.Errorf("配置文件已存在: %s，使用 --force 覆盖", file)
+	}
+
+	if o.TodoDir == "" {
+		if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("标准输入不是终端，请使用 --todo-dir 指定 todo 文件夹")
+		}
+		if err := o.prompt(bufio.NewReader(os.Stdin)); err != nil {
+			return err
+		}
+	}
+
+	todoDir, err := filepath.Abs(o.TodoDir)
+	if err != nil {
+		return err
+	}
+	o.TodoDir = todoDir
+	if _, err := os.Stat(todoDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(todoDir, 0755); err != nil {
+			return fmt.Errorf("创建 todo 文件夹失败: %w", err)
+		}
+		logger.Info("已创建 todo 文件夹: %s", todoDir)
+	}
+
+	content, err := config.RenderInit(o.InitOptions)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+	if err := fileutil.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	logger.Success("已创建配置文件: %s", file)
+
+	return validateConfig(file, nil)
+}
+
+// targetFile 返回要创建的配置文件，依次使用 --config、MYCMD_CONFIG 和 XDG 配置目录
+func (o *configInitOptions) targetFile() (string, error) {
+	file := configFile
+	if file == "" {
+		file = os.Getenv(initialize.ConfigEnv)
+	}
+	if file == "" {
+		return initialize.DefaultConfigFile()
+	}
+	return filepath.Abs(file)
+}
+
+// prompt 在终端中询问各个配置项，直接回车使用默认值
+func (o *configInitOptions) prompt(r *bufio.Reader) error {
+	ask := func(question, def string) (string, error) {
+		if def != "" {
+			fmt.Printf("%s [%s]: ", question, def)
+		} else {
+			fmt.Printf("%s: ", question)
+		}
+		answer, err := r.ReadString('\n')
+		if err != nil && answer == "" {
+			return "", fmt.Errorf("读取输入失败: %w", err)
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			return answer, nil
+		}
+		return def, nil
+	}
+
+	for o.TodoDir == "" {
+		dir, err := ask("todo 文件夹路径", "")
+		if err != nil {
+			return err
+		}
+		o.TodoDir = dir
+	}
+
+	weekStart, err := ask("每周的第一天", o.WeekStart)
+	if err != nil {
+		return err
+	}
+	o.WeekStart = weekStart
+
+	year, err := ask("MM/DD 格式日期使用的年份，0 表示使用今年", strconv.Itoa(o.CurrentYear))
+	if err != nil {
+		return err
+	}
+	if o.CurrentYear, err = strconv.Atoi(year); err != nil {
+		return fmt.Errorf("年份无效: %s", year)
+	}
+	return nil
+}
@@ -13,9 +13,17 @@ var flowCmd = &cobra.Command{
 }
 
 func init() {
+	flowCmd.PersistentFlags().BoolVar(&flow.Strict, "strict", false, "todo 文件中有任何问题（如标签格式错误、括号没有闭合）时命令失败")
+
 	// 注册 flow 子命令
 	flowCmd.AddCommand(
 		flow.NewTodoFlushCmd(),
 		flow.NewTodoArchiveCmd(),
+		flow.NewTodoAddCmd(),
+		flow.NewTodoStartCmd(),
+		flow.NewTodoDoneCmd(),
+		flow.NewTodoCancelCmd(),
+		flow.NewTodoListCmd(),
+		flow.NewBackupCmd(),
 	)
 } 
\ No newline at end of file
@@ -1,7 +1,24 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"mycmd/internal/flow"
+	"mycmd/pkg/initialize"
+	"mycmd/pkg/logger"
+)
+
+var (
+	configFile      string
+	configOverrides []string
+
+	logLevel string
+	verbose  bool
+	quiet    bool
+	logFile  string
 )
 
 var rootCmd = &cobra.Command{
@@ -9,13 +26,70 @@ var rootCmd = &cobra.Command{
 	Short: "mycmd - 管理自定义命令的工具",
 	Long: `mycmd 是一个命令行工具，用于创建、编辑和管理自己的命令。
 每个子命令对应调用不同模块目录的脚本。`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupLogger(); err != nil {
+			return err
+		}
+		if !needsConfig(cmd) {
+			return nil
+		}
+		return initialize.Init(configFile, configOverrides)
+	},
 }
 
 func Execute() error {
+	defer logger.Close()
+	// 命令结束时统一输出解析 todo 文件发现的问题
+	defer flow.PrintDiagnostics()
 	return rootCmd.Execute()
 }
 
+// setupLogger 根据 --log-level、-v、-q 和 --log-file 设置日志
+func setupLogger() error {
+	if verbose && quiet {
+		return fmt.Errorf("--verbose 和 --quiet 不能同时使用")
+	}
+
+	level, err := logger.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	if verbose {
+		level = logger.LevelDebug
+	}
+	if quiet {
+		level = logger.LevelError
+	}
+	logger.SetLevel(level)
+
+	return logger.SetLogFile(logFile)
+}
+
+// skipConfigAnnotation 命令自己加载配置，不需要在 PersistentPreRunE 中加载
+const skipConfigAnnotation = "mycmd/skip-config"
+
+// needsConfig 判断命令是否需要加载配置，help、completion 以及标记了 skipConfigAnnotation 的命令不需要
+func needsConfig(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "help" || c.Name() == "completion" || c.Annotations[skipConfigAnnotation] == "true" {
+			return false
+		}
+	}
+	return true
+}
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "",
+		"配置文件路径，默认依次使用环境变量 "+initialize.ConfigEnv+"、./config.yaml、~/.config/mycmd/config.yaml")
+	rootCmd.PersistentFlags().StringArrayVar(&configOverrides, "set", nil,
+		"覆盖配置项，格式为 key=value，如 --set flow.todo_dir=/tmp/todo，可以重复指定")
+
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info",
+		"日志级别 ("+strings.Join(logger.LevelNames, "/")+")，日志输出到标准错误")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "输出调试日志，同 --log-level debug")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "只输出错误日志，同 --log-level error")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "以 JSON 格式把日志追加写入文件")
+
 	// 注册子命令
-	rootCmd.AddCommand(flowCmd)
-} 
\ No newline at end of file
+	rootCmd.AddCommand(flowCmd, configCmd)
+}
@@ -0,0 +1,217 @@
+package flow
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mycmd/internal/flow/models"
+)
+
+// Archive 归档数据，由 ArchiveFormatter 渲染为归档文件内容
+type Archive struct {
+	Type  string            // todo 类型，如 work
+	Start time.Time         // 归档范围的第一天
+	End   time.Time         // 归档范围的最后一天
+	Tasks []models.TaskInfo // 归档范围内的任务
+}
+
+// Range 返回 YYYY-MM-DD~YYYY-MM-DD 格式的归档范围
+func (a *Archive) Range() string {
+	return dateRange{Start: a.Start, End: a.End}.String()
+}
+
+// ArchiveFormatter 归档文件格式
+type ArchiveFormatter interface {
+	// Extension 返回归档文件的扩展名，如 .md
+	Extension() string
+	// Format 将归档数据渲染为文件内容
+	Format(archive *Archive) ([]byte, error)
+}
+
+// archiveFormat 由扩展名和渲染函数组成的 ArchiveFormatter
+type archiveFormat struct {
+	extension string
+	format    func(archive *Archive) ([]byte, error)
+}
+
+func (f archiveFormat) Extension() string {
+	return f.extension
+}
+
+func (f archiveFormat) Format(archive *Archive) ([]byte, error) {
+	return f.format(archive)
+}
+
+// defaultArchiveFormat todo-archive 默认使用的归档格式
+const defaultArchiveFormat = "text"
+
+var archiveFormatters = map[string]ArchiveFormatter{
+	"text":     archiveFormat{extension: ".archive", format: formatArchiveText},
+	"markdown": archiveFormat{extension: ".md", format: formatArchiveMarkdown},
+	"json":     archiveFormat{extension: ".json", format: formatArchiveJSON},
+	"csv":      archiveFormat{extension: ".csv", format: formatArchiveCSV},
+	"html":     archiveFormat{extension: ".html", format: formatArchiveHTML},
+}
+
+// RegisterArchiveFormatter 注册归档格式，注册后可以通过 todo-archive --format name 使用
+// 同名的格式会被覆盖
+func RegisterArchiveFormatter(name string, formatter ArchiveFormatter) {
+	archiveFormatters[name] = formatter
+}
+
+// archiveFormatNames 返回所有已注册的归档格式名称
+func archiveFormatNames() []string {
+	names := make([]string, 0, len(archiveFormatters))
+	for name := range archiveFormatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getArchiveFormatter 按名称查找归档格式
+func getArchiveFormatter(name string) (ArchiveFormatter, error) {
+	formatter, ok := archiveFormatters[name]
+	if !ok {
+		return nil, fmt.Errorf("不支持的归档格式: %s，支持: %s", name, strings.Join(archiveFormatNames(), "/"))
+	}
+	return formatter, nil
+}
+
+// formatArchiveMarkdown 每个分类一个二级标题，任务以 checklist 形式列出，已取消的任务使用删除线
+func formatArchiveMarkdown(archive *Archive) ([]byte, error) {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# %s 归档 (%s)\n", archive.Type, archive.Range()))
+
+	for _, group := range groupTasksByCategory(archive.Tasks) {
+		content.WriteString(fmt.Sprintf("\n## %s\n\n", group.Category))
+
+		for _, task := range group.Tasks {
+			text := task.Name
+			if task.Project != "" {
+				text = fmt.Sprintf("[%s] %s", task.Project, text)
+			}
+
+			var details []string
+			if task.Status == models.TaskStatusInProgress {
+				details = append(details, fmt.Sprintf("%d%%", task.Percent))
+			}
+			if dateRange := task.DateRange(); dateRange != "" {
+				details = append(details, dateRange)
+			}
+			if len(details) > 0 {
+				text = fmt.Sprintf("%s (%s)", text, strings.Join(details, ", "))
+			}
+
+			switch task.Status {
+			case models.TaskStatusDone:
+				content.WriteString("- [x] " + text + "\n")
+			case models.TaskStatusCancel:
+				content.WriteString("- [ ] ~~" + text + "~~\n")
+			default:
+				content.WriteString("- [ ] " + text + "\n")
+			}
+		}
+	}
+
+	return []byte(content.String()), nil
+}
+
+func formatArchiveJSON(archive *Archive) ([]byte, error) {
+	tasks := archive.Tasks
+	if tasks == nil {
+		tasks = []models.TaskInfo{}
+	}
+
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func formatArchiveCSV(archive *Archive) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"status", "start_date", "end_date", "category", "project", "name", "percent"}); err != nil {
+		return nil, err
+	}
+	for _, task := range archive.Tasks {
+		var startDate, endDate string
+		if task.StartDate != nil {
+			startDate = task.StartDate.String()
+		}
+		if task.EndDate != nil {
+			endDate = task.EndDate.String()
+		}
+
+		record := []string{
+			string(task.Status), startDate, endDate, task.Category, task.Project, task.Name, strconv.Itoa(task.Percent),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+var archiveHTMLTemplate = template.Must(template.New("archive").Funcs(template.FuncMap{
+	"statusClass": statusClass,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Type}} 归档 ({{.Range}})</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; margin: 2em auto; max-width: 960px; color: #333; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ddd; padding: 6px 10px; text-align: left; }
+th { background: #f5f5f5; }
+.done { color: #2e7d32; }
+.doing { color: #1565c0; }
+.cancel { color: #999; text-decoration: line-through; }
+</style>
+</head>
+<body>
+<h1>{{.Type}} 归档 ({{.Range}})</h1>
+{{- range .Groups}}
+<h2>{{.Category}}</h2>
+<table>
+<tr><th>状态</th><th>项目</th><th>名称</th><th>进度</th><th>时间</th></tr>
+{{- range .Tasks}}
+<tr class="{{statusClass .Status}}"><td>{{.Status}}</td><td>{{.Project}}</td><td>{{.Name}}</td><td>{{.Percent}}%</td><td>{{.DateRange}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+func formatArchiveHTML(archive *Archive) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := archiveHTMLTemplate.Execute(&buf, archive); err != nil {
+		return nil, fmt.Errorf("生成 html 归档失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// statusClass 返回任务状态对应的 css class
+func statusClass(status models.TaskStatus) string {
+	switch status {
+	case models.TaskStatusDone:
+		return "done"
+	case models.TaskStatusCancel:
+		return "cancel"
+	}
+	return "doing"
+}
@@ -0,0 +1,105 @@
+package flow
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"mycmd/internal/flow/models"
+)
+
+func newTestArchive() *Archive {
+	return &Archive{
+		Type:  "work",
+		Start: time.Date(2024, 11, 18, 0, 0, 0, 0, time.Local),
+		End:   time.Date(2024, 11, 24, 0, 0, 0, 0, time.Local),
+		Tasks: []models.TaskInfo{
+			{
+				Status:    models.TaskStatusDone,
+				StartDate: models.NewTaskTime(24, 11, 20, 10, 0),
+				EndDate:   models.NewTaskTime(24, 11, 21, 18, 0),
+				Category:  "FEATURE",
+				Project:   "BCS",
+				Name:      "完成功能开发",
+			},
+			{
+				Status:  models.TaskStatusCancel,
+				EndDate: models.NewTaskTime(24, 11, 22, 9, 0),
+				Name:    "取消的任务",
+			},
+			{
+				Status:   models.TaskStatusInProgress,
+				Category: "BUGFIX",
+				Name:     "修复 <script> 注入",
+				Percent:  50,
+			},
+		},
+	}
+}
+
+func TestFormatArchiveMarkdown(t *testing.T) {
+	content, err := formatArchiveMarkdown(newTestArchive())
+	assert.NoError(t, err)
+
+	expected := "# work 归档 (2024-11-18~2024-11-24)\n" +
+		"\n## BUGFIX\n\n" +
+		"- [ ] 修复 <script> 注入 (50%)\n" +
+		"\n## FEATURE\n\n" +
+		"- [x] [BCS] 完成功能开发 (11/20)\n" +
+		"\n## OTHER\n\n" +
+		"- [ ] ~~取消的任务 (11/22)~~\n"
+	assert.Equal(t, expected, string(content))
+}
+
+func TestFormatArchiveJSON(t *testing.T) {
+	archive := newTestArchive()
+	content, err := formatArchiveJSON(archive)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"start_date": "24-11-20 10:00"`)
+
+	var tasks []models.TaskInfo
+	assert.NoError(t, json.Unmarshal(content, &tasks))
+	assert.Equal(t, archive.Tasks, tasks)
+
+	content, err = formatArchiveJSON(&Archive{})
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", string(content))
+}
+
+func TestFormatArchiveCSV(t *testing.T) {
+	content, err := formatArchiveCSV(newTestArchive())
+	assert.NoError(t, err)
+
+	expected := "status,start_date,end_date,category,project,name,percent\n" +
+		"已完成,24-11-20 10:00,24-11-21 18:00,FEATURE,BCS,完成功能开发,0\n" +
+		"已取消,,24-11-22 09:00,,,取消的任务,0\n" +
+		"进行中,,,BUGFIX,,修复 <script> 注入,50\n"
+	assert.Equal(t, expected, string(content))
+}
+
+func TestFormatArchiveHTML(t *testing.T) {
+	content, err := formatArchiveHTML(newTestArchive())
+	assert.NoError(t, err)
+
+	html := string(content)
+	assert.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	assert.Contains(t, html, "<title>work 归档 (2024-11-18~2024-11-24)</title>")
+	assert.Contains(t, html, `<tr class="done"><td>已完成</td><td>BCS</td><td>完成功能开发</td><td>0%</td><td>11/20</td></tr>`)
+	assert.Contains(t, html, "修复 &lt;script&gt; 注入")
+	assert.Less(t, strings.Index(html, "<h2>FEATURE</h2>"), strings.Index(html, "<h2>OTHER</h2>"))
+}
+
+func TestGetArchiveFormatter(t *testing.T) {
+	RegisterArchiveFormatter("test", archiveFormat{extension: ".txt"})
+	defer delete(archiveFormatters, "test")
+
+	formatter, err := getArchiveFormatter("test")
+	assert.NoError(t, err)
+	assert.Equal(t, ".txt", formatter.Extension())
+
+	_, err = getArchiveFormatter("pdf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package flow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"mycmd/internal/flow/models"
+)
+
+// defaultArchiveTemplate text 格式使用的模板
+// format1 逐行列出所有任务，format2 把已完成和进行中的任务按照分类罗列
+const defaultArchiveTemplate = `---------------------------------------------
+format1. 状态-开始时间-结束时间-分类-项目-名称
+---------------------------------------------
+
+{{range .Tasks}}{{.String}}
+{{end}}
+
+---------------------------------------------
+format2. (把已完成和进行中的任务按照分类罗列)
+---------------------------------------------
+{{range .Groups}}{{$category := .Category}}{{with excludeStatus "已取消" .Tasks}}
+{{$category}}:
+{{range $i, $task := .}}{{inc $i}}. {{brief $task}}
+{{end}}{{end}}{{end}}`
+
+// archiveTemplateFuncs 归档模板中可以使用的函数
+//
+//	date "01/02" .Start           格式化 time.Time 或 *models.TaskTime，为空时返回空串
+//	percent 3 4                   计算百分比，返回 75%
+//	byStatus "已完成" .Tasks       过滤指定状态的任务
+//	excludeStatus "已取消" .Tasks  排除指定状态的任务
+//	brief $task                   不含分类的任务摘要，已完成的任务省略状态
+//	inc $i                        加一，用于从 1 开始编号
+//	join ", " $list               拼接字符串
+var archiveTemplateFuncs = template.FuncMap{
+	"date":          formatTemplateDate,
+	"percent":       formatPercent,
+	"byStatus":      filterTasksByStatus(true),
+	"excludeStatus": filterTasksByStatus(false),
+	"brief":         briefTask,
+	"inc":           func(i int) int { return i + 1 },
+	"join":          strings.Join,
+}
+
+// Groups 按分类组织任务，分类按名称排序，OTHER 放到最后
+func (a *Archive) Groups() []TaskGroup {
+	return groupTasksByCategory(a.Tasks)
+}
+
+// Count 返回指定状态的任务数量
+func (a *Archive) Count(status models.TaskStatus) int {
+	return len(filterTasksByStatus(true)(status, a.Tasks))
+}
+
+// newTemplateArchiveFormatter 读取模板文件，创建使用 text/template 渲染的归档格式
+// 归档文件的扩展名取自模板文件名，如 weekly.md.tmpl 生成 .md 文件
+func newTemplateArchiveFormatter(path string) (ArchiveFormatter, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取归档模板失败: %w", err)
+	}
+
+	tmpl, err := parseArchiveTemplate(filepath.Base(path), string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".tmpl"), ".tpl")
+	extension := filepath.Ext(name)
+	if extension == "" || name == filepath.Base(path) {
+		extension = ".archive"
+	}
+
+	return archiveFormat{
+		extension: extension,
+		format: func(archive *Archive) ([]byte, error) {
+			return executeArchiveTemplate(tmpl, archive)
+		},
+	}, nil
+}
+
+func parseArchiveTemplate(name, content string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(archiveTemplateFuncs).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("解析归档模板失败: %w", err)
+	}
+	return tmpl, nil
+}
+
+func executeArchiveTemplate(tmpl *template.Template, archive *Archive) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, archive); err != nil {
+		return nil, fmt.Errorf("渲染归档模板失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var textArchiveTemplate = template.Must(parseArchiveTemplate("text", defaultArchiveTemplate))
+
+func formatArchiveText(archive *Archive) ([]byte, error) {
+	return executeArchiveTemplate(textArchiveTemplate, archive)
+}
+
+func formatTemplateDate(layout string, value interface{}) (string, error) {
+	switch t := value.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case *models.TaskTime:
+		if t == nil {
+			return "", nil
+		}
+		return t.Time().Format(layout), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("date 不支持的类型: %T", value)
+}
+
+func formatPercent(part, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", part*100/total)
+}
+
+// filterTasksByStatus keep 为 true 时保留指定状态的任务，否则排除
+func filterTasksByStatus(keep bool) func(status models.TaskStatus, tasks []models.TaskInfo) []models.TaskInfo {
+	return func(status models.TaskStatus, tasks []models.TaskInfo) []models.TaskInfo {
+		var result []models.TaskInfo
+		for _, task := range tasks {
+			if (task.Status == status) == keep {
+				result = append(result, task)
+			}
+		}
+		return result
+	}
+}
+
+func briefTask(task models.TaskInfo) string {
+	if task.Status == models.TaskStatusDone {
+		task.IgnoreStatus()
+	}
+	return task.IgnoreCategory().String()
+}
@@ -0,0 +1,104 @@
+package flow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatArchiveText(t *testing.T) {
+	archive := newTestArchive()
+	archive.Tasks = archive.Tasks[:2]
+
+	content, err := formatArchiveText(archive)
+	assert.NoError(t, err)
+
+	expected := "---------------------------------------------\n" +
+		"format1. 状态-开始时间-结束时间-分类-项目-名称\n" +
+		"---------------------------------------------\n\n" +
+		"已完成-11/20-FEATURE-BCS-完成功能开发\n" +
+		"已取消-11/22-取消的任务\n" +
+		"\n\n---------------------------------------------\n" +
+		"format2. (把已完成和进行中的任务按照分类罗列)\n" +
+		"---------------------------------------------\n" +
+		"\nFEATURE:\n" +
+		"1. 11/20-BCS-完成功能开发\n"
+	assert.Equal(t, expected, string(content))
+}
+
+func TestNewTemplateArchiveFormatter(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		extension string
+		expected  string
+		wantErr   bool
+	}{
+		{
+			name: "周报模板",
+			file: "weekly.md.tmpl",
+			content: `# {{.Type}} {{date "01/02" .Start}}~{{date "01/02" .End}}
+完成率 {{percent (.Count "已完成") (len .Tasks)}}
+{{range .Groups}}## {{.Category}}
+{{range $i, $t := byStatus "已完成" .Tasks}}{{inc $i}}. {{$t.Name}} {{date "1/2" $t.EndDate}}
+{{end}}{{end}}`,
+			extension: ".md",
+			expected: "# work 11/18~11/24\n" +
+				"完成率 33%\n" +
+				"## BUGFIX\n" +
+				"## FEATURE\n" +
+				"1. 完成功能开发 11/21\n" +
+				"## OTHER\n",
+		},
+		{
+			name:      "没有扩展名时使用 .archive",
+			file:      "weekly.tmpl",
+			content:   `{{len .Tasks}}`,
+			extension: ".archive",
+			expected:  "3",
+		},
+		{
+			name:      "不是 .tmpl 结尾时使用 .archive",
+			file:      "weekly.txt",
+			content:   `{{len .Tasks}}`,
+			extension: ".archive",
+			expected:  "3",
+		},
+		{
+			name:    "模板语法错误",
+			file:    "broken.tmpl",
+			content: `{{range .Tasks}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			formatter, err := newTemplateArchiveFormatter(path)
+			if err != nil {
+				assert.True(t, tt.wantErr, "unexpected error: %v", err)
+				return
+			}
+			assert.Equal(t, tt.extension, formatter.Extension())
+
+			content, err := formatter.Format(newTestArchive())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(content))
+		})
+	}
+
+	_, err := newTemplateArchiveFormatter(filepath.Join(dir, "missing.tmpl"))
+	assert.Error(t, err)
+}
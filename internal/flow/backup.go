@@ -0,0 +1,93 @@
+package flow
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"mycmd/pkg/logger"
+)
+
+type backupListOptions struct {
+	file string
+}
+
+// NewBackupCmd 创建 backup 命令，管理覆盖文件前自动创建的备份
+func NewBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "管理 todo 文件夹的备份",
+		Long: `flow 命令覆盖文件前会自动把原文件备份到 todo 文件夹的 .backup 目录，
+每个文件保留的数量和天数由配置 flow.backup.keep 和 flow.backup.max_days 决定。`,
+	}
+
+	cmd.AddCommand(newBackupListCmd(), newBackupRestoreCmd())
+	return cmd
+}
+
+func newBackupListCmd() *cobra.Command {
+	opts := &backupListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出备份，最新的在前",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run()
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.file, "file", "", "只列出指定文件的备份，路径相对于 todo 文件夹，如 work/work.todo")
+
+	return cmd
+}
+
+func (o *backupListOptions) run() error {
+	file := o.file
+	if file != "" {
+		file = filepath.Clean(file)
+	}
+
+	backups, err := newBackupManager().List(file)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		logger.Warning("没有找到备份")
+		return nil
+	}
+
+	rows := [][]string{{"ID", "备份时间", "文件", "大小"}}
+	for _, b := range backups {
+		rows = append(rows, []string{
+			b.ID,
+			b.Time.Format("2006-01-02 15:04:05"),
+			b.Source,
+			fmt.Sprintf("%d", b.Size),
+		})
+	}
+	lines := alignColumns(rows)
+	logger.Print(logger.StyleInfo, "%s", lines[0])
+	for _, line := range lines[1:] {
+		logger.Print(logger.StylePlain, "%s", line)
+	}
+	logger.Print(logger.StyleInfo, "共 %d 个备份", len(backups))
+	return nil
+}
+
+func newBackupRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <id>",
+		Short: "使用备份恢复文件，恢复前会先备份文件当前的内容",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := newBackupManager().Restore(args[0])
+			if err != nil {
+				return err
+			}
+			logger.Success("已使用 %s 的备份 [%s] 恢复文件: %s", b.Time.Format("2006-01-02 15:04:05"), b.ID, b.Source)
+			return nil
+		},
+	}
+}
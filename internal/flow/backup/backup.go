@@ -0,0 +1,259 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mycmd/pkg/fileutil"
+)
+
+const (
+	// DirName 备份目录名称，位于 todo 文件夹下
+	DirName = ".backup"
+
+	// DefaultKeep 每个文件默认保留的备份数量
+	DefaultKeep = 10
+
+	// timeLayout 备份文件名中的时间格式
+	timeLayout = "20060102-150405.000"
+	// separator 备份文件名中原文件名和时间的分隔符，如 work.todo~20241122-150405.123
+	separator = "~"
+	// idLength 备份 ID 的长度
+	idLength = 8
+)
+
+// Backup 一个备份文件
+type Backup struct {
+	ID     string    // 备份 ID
+	Path   string    // 备份文件路径
+	Source string    // 原文件相对于 todo 文件夹的路径
+	Time   time.Time // 备份时间
+	Size   int64     // 文件大小
+}
+
+// Manager 管理 todo 文件夹下的备份
+type Manager struct {
+	Root   string        // todo 文件夹
+	Keep   int           // 每个文件保留的备份数量
+	MaxAge time.Duration // 备份保留的时长，0 表示不限制
+}
+
+// NewManager 创建备份管理器，keep 小于等于 0 时使用 DefaultKeep
+func NewManager(root string, keep int, maxAge time.Duration) *Manager {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	return &Manager{Root: root, Keep: keep, MaxAge: maxAge}
+}
+
+// Dir 返回备份目录
+func (m *Manager) Dir() string {
+	return filepath.Join(m.Root, DirName)
+}
+
+// Snapshot 备份文件当前的内容，并按保留策略清理旧的备份
+// 文件不存在或内容与 data 相同时不备份，返回 nil
+func (m *Manager) Snapshot(path string, data []byte) (*Backup, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if data != nil && bytes.Equal(content, data) {
+		return nil, nil
+	}
+
+	source, err := m.source(path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	backupPath := filepath.Join(m.Dir(), source+separator+now.Format(timeLayout))
+	if err := os.MkdirAll(filepath.Dir(backupPath), 0755); err != nil {
+		return nil, fmt.Errorf("创建备份目录失败: %w", err)
+	}
+	// 原子写入，中断时不会留下不完整的备份，清理旧备份时也不会因此删除完整的备份
+	if err := fileutil.WriteFile(backupPath, content, 0644); err != nil {
+		return nil, fmt.Errorf("写入备份文件失败: %w", err)
+	}
+
+	if err := m.prune(source); err != nil {
+		return nil, err
+	}
+
+	return m.newBackup(backupPath, source, now, int64(len(content))), nil
+}
+
+// List 返回所有备份，最新的在前。source 不为空时只返回该文件的备份
+func (m *Manager) List(source string) ([]Backup, error) {
+	var backups []Backup
+
+	err := filepath.WalkDir(m.Dir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == m.Dir() {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(m.Dir(), path)
+		if err != nil {
+			return err
+		}
+		idx := strings.LastIndex(rel, separator)
+		if idx == -1 {
+			return nil
+		}
+		t, err := time.ParseInLocation(timeLayout, rel[idx+len(separator):], time.Local)
+		if err != nil {
+			// 不是备份文件
+			return nil
+		}
+
+		name := filepath.ToSlash(rel[:idx])
+		if source != "" && name != filepath.ToSlash(source) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		backups = append(backups, *m.newBackup(path, name, t, info.Size()))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// Find 按 ID 查找备份，支持 ID 前缀
+func (m *Manager) Find(id string) (*Backup, error) {
+	if id == "" {
+		return nil, fmt.Errorf("备份 ID 不能为空")
+	}
+
+	backups, err := m.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Backup
+	for _, b := range backups {
+		if strings.HasPrefix(b.ID, id) {
+			matched = append(matched, b)
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("备份不存在: %s", id)
+	case 1:
+		return &matched[0], nil
+	}
+	return nil, fmt.Errorf("备份 ID %s 匹配到 %d 个备份，请输入更长的 ID", id, len(matched))
+}
+
+// Restore 使用备份覆盖原文件，覆盖前先备份原文件当前的内容
+func (m *Manager) Restore(id string) (*Backup, error) {
+	b, err := m.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(b.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份文件失败: %w", err)
+	}
+
+	target := filepath.Join(m.Root, filepath.FromSlash(b.Source))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	lock, err := fileutil.LockFile(target)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	if _, err := m.Snapshot(target, content); err != nil {
+		return nil, err
+	}
+	if err := fileutil.WriteFile(target, content, 0644); err != nil {
+		return nil, fmt.Errorf("恢复文件失败: %w", err)
+	}
+
+	return b, nil
+}
+
+// prune 按保留策略删除 source 的旧备份，最新的备份总是保留
+func (m *Manager) prune(source string) error {
+	backups, err := m.List(source)
+	if err != nil {
+		return err
+	}
+
+	for i, b := range backups {
+		if i == 0 {
+			continue
+		}
+		expired := m.MaxAge > 0 && time.Since(b.Time) > m.MaxAge
+		if i < m.Keep && !expired {
+			continue
+		}
+		if err := os.Remove(b.Path); err != nil {
+			return fmt.Errorf("删除旧备份失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// source 返回文件相对于 todo 文件夹的路径，不在 todo 文件夹下的文件使用文件名
+func (m *Manager) source(path string) (string, error) {
+	absRoot, err := filepath.Abs(m.Root)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(path), nil
+	}
+	return rel, nil
+}
+
+func (m *Manager) newBackup(path, source string, t time.Time, size int64) *Backup {
+	rel, _ := filepath.Rel(m.Dir(), path)
+	sum := sha1.Sum([]byte(filepath.ToSlash(rel)))
+	return &Backup{
+		ID:     hex.EncodeToString(sum[:])[:idLength],
+		Path:   path,
+		Source: filepath.ToSlash(source),
+		Time:   t,
+		Size:   size,
+	}
+}
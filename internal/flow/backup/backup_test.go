@@ -0,0 +1,191 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// snapshot 备份后等待，保证备份文件名中的时间不同
+func snapshot(t *testing.T, m *Manager, path, data string) *Backup {
+	t.Helper()
+	b, err := m.Snapshot(path, []byte(data))
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	return b
+}
+
+func TestSnapshot(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root, 0, 0)
+	path := filepath.Join(root, "work", "work.todo")
+
+	// 文件不存在时不备份
+	assert.Nil(t, snapshot(t, m, path, "v1"))
+
+	writeTestFile(t, path, "v1")
+
+	// 内容相同时不备份
+	assert.Nil(t, snapshot(t, m, path, "v1"))
+
+	b := snapshot(t, m, path, "v2")
+	require.NotNil(t, b)
+	assert.Equal(t, "work/work.todo", b.Source)
+	assert.Equal(t, int64(2), b.Size)
+	assert.Len(t, b.ID, idLength)
+
+	content, err := os.ReadFile(b.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+	assert.Equal(t, filepath.Join(root, DirName, "work"), filepath.Dir(b.Path))
+}
+
+func TestList(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root, 0, 0)
+
+	backups, err := m.List("")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	work := filepath.Join(root, "work", "work.todo")
+	study := filepath.Join(root, "study", "study.todo")
+	writeTestFile(t, work, "w1")
+	writeTestFile(t, study, "s1")
+
+	first := snapshot(t, m, work, "w2")
+	snapshot(t, m, study, "s2")
+	last := snapshot(t, m, work, "w3")
+
+	backups, err = m.List("")
+	require.NoError(t, err)
+	assert.Len(t, backups, 3)
+
+	backups, err = m.List("work/work.todo")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, last.ID, backups[0].ID)
+	assert.Equal(t, first.ID, backups[1].ID)
+
+	// 备份目录中的其它文件被忽略
+	writeTestFile(t, filepath.Join(m.Dir(), "README"), "")
+	backups, err = m.List("")
+	require.NoError(t, err)
+	assert.Len(t, backups, 3)
+}
+
+func TestSnapshotKeep(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root, 2, 0)
+	path := filepath.Join(root, "work.todo")
+	writeTestFile(t, path, "v0")
+
+	var ids []string
+	for _, data := range []string{"v1", "v2", "v3", "v4"} {
+		b := snapshot(t, m, path, data)
+		require.NotNil(t, b)
+		ids = append(ids, b.ID)
+		writeTestFile(t, path, data)
+	}
+
+	backups, err := m.List("work.todo")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, ids[3], backups[0].ID)
+	assert.Equal(t, ids[2], backups[1].ID)
+}
+
+func TestSnapshotMaxAge(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root, 10, time.Hour)
+	path := filepath.Join(root, "work.todo")
+	writeTestFile(t, path, "v0")
+
+	// 过期的备份
+	old := time.Now().Add(-2 * time.Hour).Format(timeLayout)
+	writeTestFile(t, filepath.Join(m.Dir(), "work.todo"+separator+old), "old")
+
+	// 只有一个过期的备份时，最新的备份总是保留
+	require.NoError(t, m.prune("work.todo"))
+	backups, err := m.List("work.todo")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+
+	b := snapshot(t, m, path, "v1")
+	backups, err = m.List("work.todo")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, b.ID, backups[0].ID)
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root, 0, 0)
+	path := filepath.Join(root, "work.todo")
+	writeTestFile(t, path, "v1")
+	b := snapshot(t, m, path, "v2")
+
+	found, err := m.Find(b.ID[:4])
+	require.NoError(t, err)
+	assert.Equal(t, b.Path, found.Path)
+
+	_, err = m.Find("zzzz")
+	assert.Error(t, err)
+
+	_, err = m.Find("")
+	assert.Error(t, err)
+}
+
+func TestRestore(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root, 0, 0)
+	path := filepath.Join(root, "work", "work.todo")
+	writeTestFile(t, path, "v1")
+
+	b := snapshot(t, m, path, "v2")
+	writeTestFile(t, path, "v2")
+
+	restored, err := m.Restore(b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, b.ID, restored.ID)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+
+	// 恢复前备份了当前的内容
+	backups, err := m.List("work/work.todo")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	content, err = os.ReadFile(backups[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+}
+
+func TestManager_ListIgnoresTempFiles(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root, 10, 0)
+
+	path := filepath.Join(root, "work", "work.todo")
+	writeTestFile(t, path, "v1")
+	_, err := m.Snapshot(path, []byte("v2"))
+	require.NoError(t, err)
+
+	// 原子写入被中断时留下的临时文件不是备份
+	tmp := filepath.Join(m.Dir(), "work", ".work.todo~20240101-100000.000.tmp123")
+	require.NoError(t, os.WriteFile(tmp, []byte("v"), 0644))
+
+	backups, err := m.List("")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
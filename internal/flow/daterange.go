@@ -0,0 +1,148 @@
+package flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mycmd/internal/flow/models"
+	"mycmd/pkg/config"
+	"mycmd/pkg/logger"
+)
+
+// dateRange 日期范围，Start 和 End 都是当天的 0 点，包含首尾两天
+type dateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// dateRangeFormat 日期范围参数支持的格式
+const dateRangeFormat = "MM/DD,MM/DD、YY-MM-DD,YY-MM-DD 或 YYYY-MM-DD,YYYY-MM-DD"
+
+// parseDateRange 解析日期范围，MM/DD 格式的日期使用 year 作为年份
+func parseDateRange(value string, year int) (dateRange, error) {
+	dates := strings.Split(value, ",")
+	if len(dates) != 2 {
+		return dateRange{}, fmt.Errorf("日期格式错误，应为: %s", dateRangeFormat)
+	}
+	return newDateRange(strings.TrimSpace(dates[0]), strings.TrimSpace(dates[1]), year)
+}
+
+// newDateRange 根据开始和结束日期构建日期范围
+func newDateRange(rangeStart, rangeEnd string, year int) (dateRange, error) {
+	start, _, err := parseRangeDate(rangeStart, year)
+	if err != nil {
+		return dateRange{}, err
+	}
+	end, endHasYear, err := parseRangeDate(rangeEnd, year)
+	if err != nil {
+		return dateRange{}, err
+	}
+
+	if end.Before(start) {
+		if endHasYear {
+			return dateRange{}, fmt.Errorf("结束日期 %s 早于开始日期 %s", rangeEnd, rangeStart)
+		}
+		// 处理跨年的情况
+		end = end.AddDate(1, 0, 0)
+		logger.Debug("跨年处理: 调整范围结束时间 +1 年")
+	}
+
+	return dateRange{Start: start, End: end}, nil
+}
+
+// parseRangeDate 解析 YYYY-MM-DD、YY-MM-DD 或 MM/DD 格式的日期
+// MM/DD 格式使用 year 作为年份，hasYear 表示日期本身是否包含年份
+func parseRangeDate(date string, year int) (t time.Time, hasYear bool, err error) {
+	var parts []string
+	if strings.Contains(date, "-") {
+		parts = strings.Split(date, "-")
+		if len(parts) != 3 {
+			return time.Time{}, false, fmt.Errorf("无效的日期格式: %s", date)
+		}
+		hasYear = true
+	} else {
+		parts = strings.Split(date, "/")
+		if len(parts) != 2 {
+			return time.Time{}, false, fmt.Errorf("无效的日期格式: %s", date)
+		}
+		parts = append([]string{strconv.Itoa(year)}, parts...)
+	}
+
+	y, err := strconv.Atoi(parts[0])
+	if err != nil || (len(parts[0]) != 2 && len(parts[0]) != 4) {
+		return time.Time{}, false, fmt.Errorf("无效的年份: %s", date)
+	}
+	if y < 100 {
+		y += 2000
+	}
+	month, err := strconv.Atoi(parts[1])
+	if err != nil || month < 1 || month > 12 {
+		return time.Time{}, false, fmt.Errorf("无效的月份: %s", date)
+	}
+	day, err := strconv.Atoi(parts[2])
+	if err != nil || day < 1 || day > 31 {
+		return time.Time{}, false, fmt.Errorf("无效的日期: %s", date)
+	}
+
+	t = time.Date(y, time.Month(month), day, 0, 0, 0, 0, time.Local)
+	if t.Day() != day {
+		return time.Time{}, false, fmt.Errorf("无效的日期: %s", date)
+	}
+	return t, hasYear, nil
+}
+
+// resolveYear 返回 MM/DD 格式日期使用的年份
+// 优先使用命令行参数，其次是配置文件中的 flow.current_year，都没有时使用今年
+func resolveYear(year int) int {
+	if year > 0 {
+		return year
+	}
+	if year := config.Get().Flow.CurrentYear; year > 0 {
+		return year
+	}
+	return time.Now().Year()
+}
+
+// String 返回 YYYY-MM-DD~YYYY-MM-DD 格式的日期范围
+func (r dateRange) String() string {
+	return fmt.Sprintf("%s~%s", r.Start.Format("2006-01-02"), r.End.Format("2006-01-02"))
+}
+
+// overlaps 检查任务时间范围与日期范围是否存在交集
+func (r dateRange) overlaps(taskStartTime, taskEndTime *models.TaskTime) bool {
+	// 如果任务没有开始时间和结束时间，直接返回 false
+	if taskStartTime == nil && taskEndTime == nil {
+		return false
+	}
+
+	// 将 TaskTime 转换为 time.Time
+	parseTaskTime := func(t *models.TaskTime) time.Time {
+		return time.Date(2000+t.Year, time.Month(t.Month), t.Day, 0, 0, 0, 0, time.Local)
+	}
+
+	// 如果只有结束时间，且结束时间在范围内，则符合条件
+	if taskStartTime == nil && taskEndTime != nil {
+		taskEnd := parseTaskTime(taskEndTime)
+		result := !taskEnd.Before(r.Start) && !taskEnd.After(r.End)
+		return result
+	}
+
+	// 正常情况：有开始时间
+	taskStart := parseTaskTime(taskStartTime)
+	var taskEnd time.Time
+	if taskEndTime != nil {
+		taskEnd = parseTaskTime(taskEndTime)
+	} else {
+		taskEnd = time.Now() // 如果没有结束时间，表示至今
+	}
+
+	// 判断时间范围是否有重叠
+	// 两个时间范围有重叠的条件是:
+	// !(任务结束 < 范围开始 || 任务开始 > 范围结束)
+	result := !(taskEnd.Before(r.Start) || taskStart.After(r.End))
+	logger.Debug("日期范围检查结果: %v (范围: %s ~ %s, 任务: %s ~ %v)", result,
+		r.Start.Format("01/02"), r.End.Format("01/02"), taskStart.Format("01/02"), taskEnd.Format("01/02"))
+	return result
+}
@@ -0,0 +1,40 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		year    int
+		want    string
+		wantErr bool
+	}{
+		{name: "MM/DD 使用指定年份", value: "11/18,11/24", year: 2023, want: "2023-11-18~2023-11-24"},
+		{name: "MM/DD 跨年", value: "12/20,01/10", year: 2024, want: "2024-12-20~2025-01-10"},
+		{name: "YYYY-MM-DD", value: "2024-12-30,2025-01-05", year: 2026, want: "2024-12-30~2025-01-05"},
+		{name: "YY-MM-DD", value: "24-11-18, 24-11-24", year: 2026, want: "2024-11-18~2024-11-24"},
+		{name: "混合格式", value: "2024-12-30,01/05", year: 2024, want: "2024-12-30~2025-01-05"},
+		{name: "带年份的结束日期早于开始日期", value: "2024-12-30,2024-01-05", wantErr: true},
+		{name: "无效日期", value: "2024-02-30,2024-03-01", wantErr: true},
+		{name: "无效月份", value: "13/01,13/02", year: 2024, wantErr: true},
+		{name: "缺少结束日期", value: "11/18", year: 2024, wantErr: true},
+		{name: "无效年份", value: "202-11-18,202-11-24", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDateRange(tt.value, tt.year)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package flow
+
+import (
+	"fmt"
+
+	"mycmd/internal/flow/todofile"
+	"mycmd/pkg/logger"
+)
+
+// Strict 为 true 时 todo 文件中有任何问题都会让命令失败，对应 flow 命令的 --strict 参数
+var Strict bool
+
+// diagnostics 命令执行过程中解析 todo 文件发现的问题，命令结束时由 PrintDiagnostics 统一输出
+var diagnostics []todofile.Diagnostic
+
+// parseTodoFile 解析 todo 文件并记录其中的问题
+func parseTodoFile(path string) (*todofile.Document, error) {
+	doc, err := todofile.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDiagnostics(doc, path); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// checkDiagnostics 记录文档中的问题，没有文件路径的问题使用 path
+// --strict 时有问题则立即输出所有问题并返回错误，避免在有问题的文件上继续修改
+func checkDiagnostics(doc *todofile.Document, path string) error {
+	for _, d := range doc.Diagnostics {
+		if d.File == "" {
+			d.File = path
+		}
+		diagnostics = append(diagnostics, d)
+	}
+
+	if Strict && len(doc.Diagnostics) > 0 {
+		PrintDiagnostics()
+		return fmt.Errorf("%s 中有 %d 个问题 (--strict)", path, len(doc.Diagnostics))
+	}
+	return nil
+}
+
+// PrintDiagnostics 按编译器的格式输出记录的问题以及问题的数量，输出后清空
+func PrintDiagnostics() {
+	if len(diagnostics) == 0 {
+		return
+	}
+
+	errors := 0
+	for _, d := range diagnostics {
+		if d.Severity == todofile.SeverityError {
+			errors++
+			logger.Error("%s", d)
+		} else {
+			logger.Warning("%s", d)
+		}
+	}
+	logger.Warning("共 %d 个问题: %d 个错误，%d 个警告", len(diagnostics), errors, len(diagnostics)-errors)
+
+	diagnostics = nil
+}
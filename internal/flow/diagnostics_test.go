@@ -0,0 +1,36 @@
+package flow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTodoFile_Diagnostics(t *testing.T) {
+	defer func() { Strict, diagnostics = false, nil }()
+
+	path := filepath.Join(t.TempDir(), "work.todo")
+	require.NoError(t, os.WriteFile(path, []byte("工作:\n    ☐ 任务 @progress(abc)\n"), 0644))
+
+	doc, err := parseTodoFile(path)
+	require.NoError(t, err)
+	assert.Len(t, doc.Tasks(), 1)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, path, diagnostics[0].File)
+	assert.Equal(t, 2, diagnostics[0].Line)
+
+	PrintDiagnostics()
+	assert.Empty(t, diagnostics)
+
+	Strict = true
+	_, err = parseTodoFile(path)
+	assert.EqualError(t, err, path+" 中有 1 个问题 (--strict)")
+
+	// 没有问题的文件不受 --strict 影响，任意的 @key(value) 标签不是问题
+	require.NoError(t, os.WriteFile(path, []byte("工作:\n    ☐ 任务 @progress(50) @review @owner(alice)\n"), 0644))
+	_, err = parseTodoFile(path)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,46 @@
+package flow
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"mycmd/pkg/logger"
+)
+
+// unifiedDiff 返回修改前后内容的 unified diff，内容相同时返回空串
+func unifiedDiff(name string, before, after []byte) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(before),
+		B:        splitLines(after),
+		FromFile: name,
+		ToFile:   name + " (修改后)",
+		Context:  3,
+	})
+}
+
+// splitLines 按行拆分内容，每行都以换行符结尾
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// printDiff 把 diff 输出到标准输出，新增的行为绿色，删除的行为红色
+func printDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		style := logger.StylePlain
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+			style = logger.StyleInfo
+		case strings.HasPrefix(line, "+"):
+			style = logger.StyleSuccess
+		case strings.HasPrefix(line, "-"):
+			style = logger.StyleError
+		}
+		logger.Print(style, "%s", line)
+	}
+}
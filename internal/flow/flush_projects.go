@@ -0,0 +1,113 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"mycmd/internal/flow/todofile"
+	"mycmd/pkg/logger"
+)
+
+// flushProjects todo-flush 每个分类下的项目
+// 分类的项目优先使用 --project CATEGORY=a,b，其次是 --project a,b，最后是配置 flow.projects.<type>.<CATEGORY>
+type flushProjects struct {
+	Default    []string            // --project a,b 指定的项目，用于所有分类
+	Categories map[string][]string // --project CATEGORY=a,b 指定的项目
+	Config     map[string][]string // 配置 flow.projects.<type> 中每个分类的项目
+}
+
+// parseFlushProjects 解析 --project 参数，参数可以重复指定
+func parseFlushProjects(values []string, cfg map[string][]string) (*flushProjects, error) {
+	p := &flushProjects{Categories: map[string][]string{}, Config: cfg}
+	for _, value := range values {
+		category, projects, ok := strings.Cut(value, "=")
+		if !ok {
+			p.Default = append(p.Default, splitProjects(value)...)
+			continue
+		}
+
+		category = strings.TrimSpace(category)
+		if category == "" {
+			return nil, fmt.Errorf("项目格式错误，应为: CATEGORY=PROJECT1,PROJECT2 或 PROJECT1,PROJECT2: %s", value)
+		}
+		p.Categories[category] = append(p.Categories[category], splitProjects(projects)...)
+	}
+	return p, nil
+}
+
+// Of 返回分类的项目
+func (p *flushProjects) Of(category string) []string {
+	if projects, ok := p.Categories[category]; ok {
+		return projects
+	}
+	if len(p.Default) > 0 {
+		return p.Default
+	}
+	return p.Config[category]
+}
+
+// Empty 判断是否没有指定任何项目
+func (p *flushProjects) Empty() bool {
+	return len(p.Default) == 0 && len(p.Categories) == 0 && len(p.Config) == 0
+}
+
+// String 返回每个分类的项目，用于打印日志
+func (p *flushProjects) String() string {
+	var parts []string
+	if len(p.Default) > 0 {
+		parts = append(parts, strings.Join(p.Default, ","))
+	}
+	for _, category := range sortedKeys(p.Categories) {
+		parts = append(parts, category+"="+strings.Join(p.Categories[category], ","))
+	}
+	if len(parts) == 0 {
+		for _, category := range sortedKeys(p.Config) {
+			parts = append(parts, category+"="+strings.Join(p.Config[category], ","))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// validate 检查 --project 和配置中的分类是否都在模板中
+// --project 中的分类不存在时返回错误，配置中的分类不存在时只打印警告
+func (p *flushProjects) validate(doc *todofile.Document) error {
+	categories := map[string]bool{}
+	var names []string
+	for _, node := range doc.Categories() {
+		categories[node.Title] = true
+		names = append(names, node.Title)
+	}
+
+	for _, category := range sortedKeys(p.Categories) {
+		if !categories[category] {
+			return fmt.Errorf("分类 %s 不在模板中，模板中的分类: %s", category, strings.Join(names, ", "))
+		}
+	}
+	for _, category := range sortedKeys(p.Config) {
+		if !categories[category] {
+			logger.Warning("配置 flow.projects 中的分类 %s 不在模板中，模板中的分类: %s", category, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// splitProjects 拆分逗号分隔的项目列表，忽略空项目
+func splitProjects(projects string) []string {
+	var result []string
+	for _, project := range strings.Split(projects, ",") {
+		if project = strings.TrimSpace(project); project != "" {
+			result = append(result, project)
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
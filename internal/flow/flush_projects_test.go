@@ -0,0 +1,38 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlushProjects(t *testing.T) {
+	cfg := map[string][]string{"FEATURE": {"OPS"}, "STUDY": {"GO"}}
+
+	p, err := parseFlushProjects([]string{"BUGFIX=BCS, DUAL", "BUGFIX=OPS", "FEATURE="}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BCS", "DUAL", "OPS"}, p.Of("BUGFIX"))
+	assert.Empty(t, p.Of("FEATURE"))
+	assert.Equal(t, []string{"GO"}, p.Of("STUDY"))
+	assert.Equal(t, "BUGFIX=BCS,DUAL,OPS FEATURE=", p.String())
+
+	// 没有指定分类时使用 --project a,b，其次是配置
+	p, err = parseFlushProjects([]string{"BCS,DUAL"}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BCS", "DUAL"}, p.Of("FEATURE"))
+
+	p, err = parseFlushProjects(nil, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"OPS"}, p.Of("FEATURE"))
+	assert.Nil(t, p.Of("BUGFIX"))
+	assert.False(t, p.Empty())
+	assert.Equal(t, "FEATURE=OPS STUDY=GO", p.String())
+
+	p, err = parseFlushProjects(nil, nil)
+	require.NoError(t, err)
+	assert.True(t, p.Empty())
+
+	_, err = parseFlushProjects([]string{"=BCS"}, nil)
+	assert.Error(t, err)
+}
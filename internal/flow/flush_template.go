@@ -0,0 +1,95 @@
+package flow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// maxIncludeDepth include 的最大嵌套层数，避免模板互相 include 时无限递归
+const maxIncludeDepth = 10
+
+// flushTemplateData todo-flush 模板中可以使用的数据
+type flushTemplateData struct {
+	Type     string    // todo 类型
+	Date     time.Time // 当前时间
+	Weekday  string    // 星期几，如 monday
+	Year     int       // ISO 周所在的年份
+	Week     int       // ISO 周数
+	Projects []string  // --project a,b 指定的项目
+
+	projects *flushProjects
+}
+
+func newFlushTemplateData(todoType string, projects *flushProjects, now time.Time) *flushTemplateData {
+	year, week := now.ISOWeek()
+	return &flushTemplateData{
+		Type:     todoType,
+		Date:     now,
+		Weekday:  strings.ToLower(now.Weekday().String()),
+		Year:     year,
+		Week:     week,
+		Projects: projects.Default,
+		projects: projects,
+	}
+}
+
+// ProjectsOf 返回分类的项目，依次使用 --project CATEGORY=a,b、--project a,b 和配置 flow.projects
+func (d *flushTemplateData) ProjectsOf(category string) []string {
+	return d.projects.Of(category)
+}
+
+// hasTemplateActions 判断模板是否使用了 Go 模板语法，没有使用时按旧的方式在每个根分类下添加项目
+func hasTemplateActions(content []byte) bool {
+	return bytes.Contains(content, []byte("{{"))
+}
+
+// renderFlushTemplate 使用 text/template 渲染 todo-flush 模板
+//
+// 模板中除了 flushTemplateData 的字段外，还可以使用以下函数：
+//
+//	date "2006-01-02" .Date   格式化时间
+//	addDays 4 .Date           加减天数，如本周五的日期
+//	projects "BUGFIX"         分类的项目，同 .ProjectsOf
+//	include "common.todo"     渲染并插入其它模板文件，相对路径相对于当前模板所在的文件夹
+//	join ", " $list           拼接字符串
+func renderFlushTemplate(path string, content []byte, data *flushTemplateData) (string, error) {
+	return renderFlushTemplateFile(path, content, data, 0)
+}
+
+func renderFlushTemplateFile(path string, content []byte, data *flushTemplateData, depth int) (string, error) {
+	funcs := template.FuncMap{
+		"date":     formatTemplateDate,
+		"addDays":  func(days int, t time.Time) time.Time { return t.AddDate(0, 0, days) },
+		"projects": data.ProjectsOf,
+		"join":     strings.Join,
+		"include": func(name string) (string, error) {
+			if depth >= maxIncludeDepth {
+				return "", fmt.Errorf("include 嵌套超过 %d 层: %s", maxIncludeDepth, name)
+			}
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(filepath.Dir(path), name)
+			}
+			content, err := os.ReadFile(name)
+			if err != nil {
+				return "", fmt.Errorf("读取 include 文件失败: %w", err)
+			}
+			return renderFlushTemplateFile(name, content, data, depth+1)
+		},
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(funcs).Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("解析模板失败: %w", err)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
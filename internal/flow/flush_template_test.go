@@ -0,0 +1,133 @@
+package flow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderFlushTemplate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.todo"), []byte("学习:\n    ☐ 第 {{.Week}} 周周报\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "loop.todo"), []byte(`{{include "loop.todo"}}`), 0644))
+
+	projects, err := parseFlushProjects([]string{"BCS,DUAL", "FEATURE=OPS"}, nil)
+	require.NoError(t, err)
+	data := newFlushTemplateData("work", projects, time.Date(2024, 11, 18, 9, 0, 0, 0, time.Local))
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "日期",
+			content:  `// {{date "2006-01-02" .Date}}~{{date "01/02" (addDays 4 .Date)}} {{.Year}}-W{{.Week}}`,
+			expected: "// 2024-11-18~11/22 2024-W47",
+		},
+		{
+			name: "分类的项目",
+			content: `BUGFIX:
+{{range projects "BUGFIX"}}    {{.}}:
+{{end}}FEATURE:
+{{range .ProjectsOf "FEATURE"}}    {{.}}:
+{{end}}`,
+			expected: "BUGFIX:\n    BCS:\n    DUAL:\nFEATURE:\n    OPS:\n",
+		},
+		{
+			name:     "条件",
+			content:  `{{if eq .Weekday "monday"}}☐ 周会{{else}}☐ 日报{{end}}`,
+			expected: "☐ 周会",
+		},
+		{
+			name:     "include",
+			content:  `工作:{{"\n"}}{{include "common.todo"}}`,
+			expected: "工作:\n学习:\n    ☐ 第 47 周周报\n",
+		},
+		{
+			name:    "循环 include",
+			content: `{{include "loop.todo"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "include 文件不存在",
+			content: `{{include "missing.todo"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "语法错误",
+			content: `{{if}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderFlushTemplate(filepath.Join(dir, "work-template.todo"), []byte(tt.content), data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestTodoFlushOptions_processTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 11, 18, 9, 0, 0, 0, time.Local)
+	opts := &todoFlushOptions{todoType: "work"}
+
+	flush := func(file string, values []string, cfg map[string][]string) (string, error) {
+		projects, err := parseFlushProjects(values, cfg)
+		require.NoError(t, err)
+		return opts.processTemplateFile(file, projects, now)
+	}
+
+	legacy := filepath.Join(dir, "legacy.todo")
+	require.NoError(t, os.WriteFile(legacy, []byte("// 注释\nBUGFIX\n    旧项目:\nFEATURE:\n"), 0644))
+	tmpl := filepath.Join(dir, "work-template.todo")
+	require.NoError(t, os.WriteFile(tmpl, []byte("BUGFIX:\n{{range .Projects}}    {{.}}:\n{{end}}FEATURE:\n"), 0644))
+
+	// 没有模板语法时在每个根分类下添加项目
+	content, err := flush(legacy, []string{"BCS, DUAL"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "// 注释\nBUGFIX:\n    BCS:\n    DUAL:\nFEATURE:\n    BCS:\n    DUAL:\n", content)
+
+	// 按分类指定项目
+	content, err = flush(legacy, []string{"BUGFIX=BCS,DUAL", "FEATURE=BCS"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "// 注释\nBUGFIX:\n    BCS:\n    DUAL:\nFEATURE:\n    BCS:\n", content)
+
+	// 配置中的项目
+	content, err = flush(legacy, []string{"FEATURE=OPS"}, map[string][]string{"BUGFIX": {"BCS"}, "FEATURE": {"DUAL"}})
+	require.NoError(t, err)
+	assert.Equal(t, "// 注释\nBUGFIX:\n    BCS:\nFEATURE:\n    OPS:\n", content)
+
+	// 分类不在模板中
+	_, err = flush(legacy, []string{"BUGFX=BCS"}, nil)
+	assert.ErrorContains(t, err, "BUGFIX, FEATURE")
+	_, err = flush(tmpl, []string{"OTHER=BCS"}, nil)
+	assert.Error(t, err)
+
+	// 配置中的分类不在模板中时只警告
+	_, err = flush(legacy, nil, map[string][]string{"OTHER": {"BCS"}})
+	assert.NoError(t, err)
+
+	// 使用模板语法时不再添加项目
+	content, err = flush(tmpl, []string{"BCS,DUAL"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "BUGFIX:\n    BCS:\n    DUAL:\nFEATURE:\n", content)
+
+	// 旧模板的 work 类型必须指定项目
+	_, err = flush(legacy, nil, nil)
+	assert.Error(t, err)
+	_, err = flush(tmpl, nil, nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TagValueKind 标签值的类型
+type TagValueKind string
+
+const (
+	TagValueNone     TagValueKind = "none"     // 没有值，如 @today
+	TagValueTime     TagValueKind = "time"     // 时间，如 @due(24-11-30 18:00)
+	TagValueDuration TagValueKind = "duration" // 时长，如 @est(2h30m)
+	TagValueNumber   TagValueKind = "number"   // 数字，如 @priority(1)
+	TagValueString   TagValueKind = "string"   // 字符串，如 @project(BUGFIX.BCS)
+	TagValueList     TagValueKind = "list"     // 逗号分隔的列表，如 @owner(alice, bob)
+)
+
+// TagValue 标签解析后的值，Kind 决定使用哪个字段
+type TagValue struct {
+	Kind     TagValueKind
+	Raw      string // 括号中的原始内容
+	Time     *TaskTime
+	Duration time.Duration
+	Number   float64
+	Text     string
+	List     []string
+}
+
+// String 返回与 todo 文件中格式一致的值
+func (v TagValue) String() string {
+	switch v.Kind {
+	case TagValueNone:
+		return ""
+	case TagValueTime:
+		return v.Time.String()
+	case TagValueDuration:
+		return FormatLasted(v.Duration)
+	case TagValueNumber:
+		return strconv.FormatFloat(v.Number, 'f', -1, 64)
+	case TagValueList:
+		return strings.Join(v.List, ", ")
+	}
+	return v.Text
+}
+
+// MarshalJSON 时间和时长序列化为 todo 文件中的格式，没有值的标签序列化为 true
+func (v TagValue) MarshalJSON() ([]byte, error) {
+	switch v.Kind {
+	case TagValueNone:
+		return json.Marshal(true)
+	case TagValueNumber:
+		return json.Marshal(v.Number)
+	case TagValueList:
+		return json.Marshal(v.List)
+	}
+	return json.Marshal(v.String())
+}
+
+// MarshalYAML 与 MarshalJSON 一致
+func (v TagValue) MarshalYAML() (interface{}, error) {
+	switch v.Kind {
+	case TagValueNone:
+		return true, nil
+	case TagValueNumber:
+		return v.Number, nil
+	case TagValueList:
+		return v.List, nil
+	}
+	return v.String(), nil
+}
+
+// Tag 返回任务的标签值，name 可以省略 @
+func (t *TaskInfo) Tag(name string) (TagValue, bool) {
+	v, ok := t.Tags[strings.TrimPrefix(name, "@")]
+	return v, ok
+}
+
+// InferTagValue 按内容推断标签值的类型，依次尝试时间、日期、时长、数字和逗号分隔的列表，都不是时为字符串
+func InferTagValue(value string) TagValue {
+	content := strings.TrimSpace(value)
+	if content == "" {
+		return TagValue{Kind: TagValueNone, Raw: value}
+	}
+
+	if t, err := parseDatetime(content); err == nil {
+		return TagValue{Kind: TagValueTime, Raw: value, Time: t}
+	}
+	if t, err := parseDatetime(content + " 00:00"); err == nil {
+		return TagValue{Kind: TagValueTime, Raw: value, Time: t}
+	}
+	if n, err := strconv.ParseFloat(content, 64); err == nil {
+		return TagValue{Kind: TagValueNumber, Raw: value, Number: n}
+	}
+	if d, err := ParseDuration(content); err == nil {
+		return TagValue{Kind: TagValueDuration, Raw: value, Duration: d}
+	}
+	if strings.Contains(content, ",") {
+		var list []string
+		for _, item := range strings.Split(content, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return TagValue{Kind: TagValueList, Raw: value, List: list}
+	}
+	return TagValue{Kind: TagValueString, Raw: value, Text: content}
+}
+
+// durationUnits ParseDuration 支持的单位
+var durationUnits = map[byte]time.Duration{
+	'w': 7 * 24 * time.Hour,
+	'd': 24 * time.Hour,
+	'h': time.Hour,
+	'm': time.Minute,
+}
+
+// ParseDuration 解析 @lasted、@est 格式的时长，与 FormatLasted 相反
+// e.g. 1d8h、2h30m、45m、1w
+func ParseDuration(s string) (time.Duration, error) {
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return 0, fmt.Errorf("duration cannot be empty")
+	}
+
+	var total time.Duration
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return 0, fmt.Errorf("invalid duration %q, expect e.g. 1d8h, 2h30m", s)
+		}
+
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		unit, ok := durationUnits[rest[i]]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration unit %q in %q, expect w, d, h or m", rest[i], s)
+		}
+		total += time.Duration(n) * unit
+		rest = rest[i+1:]
+	}
+	return total, nil
+}
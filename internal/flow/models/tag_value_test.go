@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTag(t *testing.T) {
+	task := &TaskInfo{}
+	tags := [][2]string{
+		{"@project", "BUGFIX.BCS"},
+		{"@created", "24-11-20 10:00"},
+		{"@started", "24-11-20 11:00"},
+		{"@done", "24-11-21 18:00"},
+		{"@lasted", "1d7h"},
+		{"@est", "2h30m"},
+		{"@due", "24-11-30"},
+		{"@priority", "1"},
+		{"@progress", "50%"},
+		{"@owner", "alice, bob"},
+		{"@ticket", "BCS-12"},
+		{"@today", ""},
+	}
+	for _, tag := range tags {
+		require.NoError(t, ParseTag(tag[0], tag[1], task), tag[0])
+	}
+
+	assert.Equal(t, "BUGFIX", task.Category)
+	assert.Equal(t, "BCS", task.Project)
+	assert.Equal(t, NewTaskTime(24, 11, 20, 11, 0), task.StartDate)
+	assert.Equal(t, NewTaskTime(24, 11, 21, 18, 0), task.EndDate)
+	assert.Equal(t, 50, task.Percent)
+
+	expected := map[string]TagValue{
+		"project":  {Kind: TagValueString, Raw: "BUGFIX.BCS", Text: "BUGFIX.BCS"},
+		"created":  {Kind: TagValueTime, Raw: "24-11-20 10:00", Time: NewTaskTime(24, 11, 20, 10, 0)},
+		"started":  {Kind: TagValueTime, Raw: "24-11-20 11:00", Time: NewTaskTime(24, 11, 20, 11, 0)},
+		"done":     {Kind: TagValueTime, Raw: "24-11-21 18:00", Time: NewTaskTime(24, 11, 21, 18, 0)},
+		"lasted":   {Kind: TagValueDuration, Raw: "1d7h", Duration: 31 * time.Hour},
+		"est":      {Kind: TagValueDuration, Raw: "2h30m", Duration: 150 * time.Minute},
+		"due":      {Kind: TagValueTime, Raw: "24-11-30", Time: NewTaskTime(24, 11, 30, 0, 0)},
+		"priority": {Kind: TagValueNumber, Raw: "1", Number: 1},
+		"progress": {Kind: TagValueNumber, Raw: "50%", Number: 50},
+		"owner":    {Kind: TagValueList, Raw: "alice, bob", List: []string{"alice", "bob"}},
+		"ticket":   {Kind: TagValueString, Raw: "BCS-12", Text: "BCS-12"},
+		"today":    {Kind: TagValueNone},
+	}
+	assert.Equal(t, expected, task.Tags)
+
+	est, ok := task.Tag("@est")
+	assert.True(t, ok)
+	assert.Equal(t, "2h30m", est.String())
+
+	data, err := json.Marshal(task.Tags)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"project": "BUGFIX.BCS", "created": "24-11-20 10:00", "started": "24-11-20 11:00",
+		"done": "24-11-21 18:00", "lasted": "1d7h", "est": "2h30m", "due": "24-11-30 00:00",
+		"priority": 1, "progress": 50, "owner": ["alice", "bob"], "ticket": "BCS-12", "today": true
+	}`, string(data))
+}
+
+func TestParseTagError(t *testing.T) {
+	tests := [][2]string{
+		{"@started", ""},
+		{"@done", "24-13-01 10:00"},
+		{"@lasted", "1x"},
+		{"@est", "abc"},
+		{"@due", "tomorrow"},
+		{"@priority", "-1"},
+		{"@progress", "200"},
+		{"@project", " "},
+	}
+	for _, tt := range tests {
+		assert.Error(t, ParseTag(tt[0], tt[1], &TaskInfo{}), tt[0])
+	}
+}
+
+func TestRegisterTagParser(t *testing.T) {
+	defer func() {
+		delete(TagSet, "@size")
+		delete(builtinTagSet, "@size")
+		delete(TagParserFns, TagType("@size"))
+	}()
+
+	require.NoError(t, RegisterTagParser("size", func(value string, task *TaskInfo) (TagValue, error) {
+		switch value {
+		case "S", "M", "L":
+			return TagValue{Kind: TagValueString, Raw: value, Text: value}, nil
+		}
+		return TagValue{}, fmt.Errorf("invalid size %q", value)
+	}))
+	assert.Error(t, RegisterTagParser("bad name", nil))
+
+	task := &TaskInfo{}
+	require.NoError(t, ParseTag("@size", "M", task))
+	assert.Equal(t, "M", task.Tags["size"].Text)
+	assert.Error(t, ParseTag("@size", "XL", task))
+
+	// 注册的标签在重新加载配置后仍然有效
+	require.NoError(t, Configure(Vocabulary{}))
+	assert.Contains(t, TagSet, "@size")
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"45m", 45 * time.Minute},
+		{"2h30m", 150 * time.Minute},
+		{"1d8h", 32 * time.Hour},
+		{"1w", 7 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		d, err := ParseDuration(tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, d, tt.input)
+	}
+
+	for _, input := range []string{"", "10", "h", "1y", "1h30"} {
+		_, err := ParseDuration(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -10,13 +10,15 @@ import (
 )
 
 type TaskInfo struct {
-	Status    TaskStatus // 已完成、进行中、已取消
-	StartDate *TaskTime
-	EndDate   *TaskTime
-	Category  string // 分类 （todo 文件的根分类）
-	Project   string // 项目 （分类下的子分类）
-	Name      string // 名称
-	Percent   int    // 百分比 0-100
+	Status    TaskStatus `json:"status"` // 已完成、进行中、已取消
+	StartDate *TaskTime  `json:"start_date,omitempty"`
+	EndDate   *TaskTime  `json:"end_date,omitempty"`
+	Category  string     `json:"category"` // 分类 （todo 文件的根分类）
+	Project   string     `json:"project"`  // 项目 （分类下的子分类）
+	Name      string     `json:"name"`     // 名称
+	Percent   int        `json:"percent"`  // 百分比 0-100
+
+	Tags map[string]TagValue `json:"tags,omitempty"` // 所有标签的值，键为不带 @ 的标签名称，如 due
 }
 
 type TaskStatus string
@@ -35,19 +37,7 @@ func (t *TaskInfo) String() string {
 		res.WriteString(fmt.Sprintf("(%d%%)", t.Percent))
 	}
 
-	dateRange := ""
-	if t.StartDate != nil && t.EndDate != nil {
-		if t.StartDate.Year == t.EndDate.Year {
-			dateRange = t.StartDate.MMDD()
-		} else {
-			dateRange = fmt.Sprintf("%s~%s", t.StartDate.MMDD(), t.EndDate.MMDD())
-		}
-	} else if t.StartDate != nil {
-		dateRange = fmt.Sprintf("%s~至今(%s)", t.StartDate.MMDD(), time.Now().Format("01/02"))
-	} else if t.EndDate != nil {
-		dateRange = t.EndDate.MMDD()
-	}
-
+	dateRange := t.DateRange()
 	if dateRange != "" {
 		if res.Len() > 0 {
 			res.WriteString("-")
@@ -81,6 +71,21 @@ func (t *TaskInfo) String() string {
 	return res.String()
 }
 
+// DateRange 返回任务的时间范围，如 11/20、11/20~至今(11/22)
+func (t *TaskInfo) DateRange() string {
+	if t.StartDate != nil && t.EndDate != nil {
+		if t.StartDate.Year == t.EndDate.Year {
+			return t.StartDate.MMDD()
+		}
+		return fmt.Sprintf("%s~%s", t.StartDate.MMDD(), t.EndDate.MMDD())
+	} else if t.StartDate != nil {
+		return fmt.Sprintf("%s~至今(%s)", t.StartDate.MMDD(), time.Now().Format("01/02"))
+	} else if t.EndDate != nil {
+		return t.EndDate.MMDD()
+	}
+	return ""
+}
+
 func (t *TaskInfo) IgnoreCategory() *TaskInfo {
 	t.Category = ""
 	return t
@@ -103,6 +108,20 @@ func (t *TaskTime) String() string {
 	return fmt.Sprintf("%02d-%02d-%02d %02d:%02d", t.Year, t.Month, t.Day, t.Hour, t.Min)
 }
 
+// MarshalText 序列化为 YY-MM-DD HH:mm 格式，与 todo 文件中的时间一致
+func (t *TaskTime) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *TaskTime) UnmarshalText(text []byte) error {
+	parsed, err := parseDatetime(string(text))
+	if err != nil {
+		return err
+	}
+	*t = *parsed
+	return nil
+}
+
 func (t *TaskTime) MMDD() string {
 	return fmt.Sprintf("%02d/%02d", t.Month, t.Day)
 }
@@ -117,6 +136,39 @@ func NewTaskTime(year, month, day, hour, min int) *TaskTime {
 	}
 }
 
+// Time 将 TaskTime 转换为本地时区的 time.Time
+func (t *TaskTime) Time() time.Time {
+	return time.Date(2000+t.Year, time.Month(t.Month), t.Day, t.Hour, t.Min, 0, 0, time.Local)
+}
+
+// NewTaskTimeFromTime 将 time.Time 转换为 TaskTime，年份使用两位数
+func NewTaskTimeFromTime(t time.Time) *TaskTime {
+	return NewTaskTime(t.Year()%100, int(t.Month()), t.Day(), t.Hour(), t.Minute())
+}
+
+// FormatLasted 将持续时间格式化为 @lasted 标签的内容，精确到分钟
+// e.g. 1d8h、2h30m、45m
+func FormatLasted(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes <= 0 {
+		return "0m"
+	}
+
+	days, hours, mins := minutes/(24*60), minutes/60%24, minutes%60
+
+	var res strings.Builder
+	if days > 0 {
+		res.WriteString(fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		res.WriteString(fmt.Sprintf("%dh", hours))
+	}
+	if mins > 0 {
+		res.WriteString(fmt.Sprintf("%dm", mins))
+	}
+	return res.String()
+}
+
 // 进行中: - ❍ ❑ ■ ⬜ □ ☐ ▪ ▫ – — ≡ → › [] [ ]
 // 已完成: ✔ ✓ ☑ + [x] [X] [+]
 // 已取消: ✘ x X [-]
@@ -152,12 +204,52 @@ var SymbolSet = map[string]TaskStatus{
 	"[-]": TaskStatusCancel,
 }
 
+// DefaultSymbols 写入任务时各状态默认使用的符号
+var DefaultSymbols = map[TaskStatus]string{
+	TaskStatusInProgress: "☐",
+	TaskStatusDone:       "✔",
+	TaskStatusCancel:     "✘",
+}
+
+// 方括号风格和 ASCII 风格的符号，修改状态时保持任务原有的风格
+var (
+	bracketSymbols = map[TaskStatus]string{
+		TaskStatusInProgress: "[ ]",
+		TaskStatusDone:       "[x]",
+		TaskStatusCancel:     "[-]",
+	}
+	asciiSymbols = map[TaskStatus]string{
+		TaskStatusInProgress: "-",
+		TaskStatusDone:       "+",
+		TaskStatusCancel:     "x",
+	}
+)
+
+// StatusSymbol 返回 status 对应的符号，并尽量与当前符号 current 的风格保持一致
+// e.g. StatusSymbol(TaskStatusDone, "[ ]") 返回 "[x]"
+func StatusSymbol(status TaskStatus, current string) string {
+	if current != "" && SymbolSet[current] == status {
+		return current
+	}
+
+	switch {
+	case strings.HasPrefix(current, "["):
+		return bracketSymbols[status]
+	case current == "-" || current == "+" || current == "x" || current == "X":
+		return asciiSymbols[status]
+	}
+	return DefaultSymbols[status]
+}
+
 // @project
 // @created
 // @started
 // @done
 // @cancelled
 // @lasted
+// @est
+// @due
+// @priority
 // @progress
 type TagType string
 
@@ -168,6 +260,9 @@ const (
 	tagTypeDone      TagType = "@done"
 	tagTypeCancelled TagType = "@cancelled"
 	tagTypeLasted    TagType = "@lasted"
+	tagTypeEst       TagType = "@est"
+	tagTypeDue       TagType = "@due"
+	tagTypePriority  TagType = "@priority"
 	tagTypePercent   TagType = "@progress"
 )
 
@@ -178,121 +273,223 @@ var TagSet = map[string]TagType{
 	"@done":      tagTypeDone,
 	"@cancelled": tagTypeCancelled,
 	"@lasted":    tagTypeLasted,
+	"@est":       tagTypeEst,
+	"@due":       tagTypeDue,
+	"@priority":  tagTypePriority,
 	"@progress":  tagTypePercent,
 }
 
-var TagParserFns = map[TagType]tagParser{
+var TagParserFns = map[TagType]TagParser{
 	tagTypeProject:   parseProject,
+	tagTypeCreated:   parseCreated,
 	tagTypeStarted:   parseStarted,
 	tagTypeDone:      parseDone,
 	tagTypeCancelled: parseCancelled,
+	tagTypeLasted:    parseLasted,
+	tagTypeEst:       parseEst,
+	tagTypeDue:       parseDue,
+	tagTypePriority:  parsePriority,
 	tagTypePercent:   parseProgress,
 }
 
-type TagParseResult struct {
-	TagType TagType
-	Content string
-}
+// TagParser 解析标签括号中的内容 value（没有括号时为空串），返回标签的值
+// 除了返回值，解析函数也可以修改任务的其它字段，如 @project 设置分类和项目
+type TagParser func(value string, task *TaskInfo) (TagValue, error)
 
-type tagParser func(tagContent string, task *TaskInfo) error
+// RegisterTagParser 注册标签的解析函数，name 可以省略 @，已注册的标签会被覆盖
+// parser 为 nil 时按内容自动推断值的类型，与未注册的 @key(value) 标签一致
+func RegisterTagParser(name string, parser TagParser) error {
+	if !strings.HasPrefix(name, "@") {
+		name = "@" + name
+	}
+	if err := validTagName(name); err != nil {
+		return err
+	}
 
-// @project 的内容可能如：
-// @project(BUGFIX.BCS)
-var parseProject = tagParser(func(tagContent string, task *TaskInfo) error {
-	tagContent = strings.TrimPrefix(tagContent, "@project")
+	tagType := TagType(name)
+	if existing, ok := builtinTagSet[name]; ok {
+		tagType = existing
+	}
+	builtinTagSet[name] = tagType
+	TagSet[name] = tagType
+	if parser == nil {
+		delete(TagParserFns, tagType)
+	} else {
+		TagParserFns[tagType] = parser
+	}
+	return nil
+}
 
-	// 校验 tagContent 格式
-	if len(tagContent) == 0 {
-		return fmt.Errorf("project tag content cannot be empty")
+// ParseTag 解析任务的标签 name（如 @due），把值保存到 task.Tags 中
+// 没有注册解析函数的标签按内容自动推断值的类型
+func ParseTag(name, value string, task *TaskInfo) error {
+	parseFn := TagParserFns[TagSet[name]]
+	if parseFn == nil {
+		parseFn = parseAny
 	}
 
-	// 检查是否以括号包裹
-	if !strings.HasPrefix(tagContent, "(") || !strings.HasSuffix(tagContent, ")") {
-		return fmt.Errorf("project tag content must be wrapped in parentheses")
+	tagValue, err := parseFn(value, task)
+	if err != nil {
+		return err
 	}
 
-	// 提取括号中的内容
-	projectContent := strings.TrimSpace(tagContent[1 : len(tagContent)-1])
+	if task.Tags == nil {
+		task.Tags = map[string]TagValue{}
+	}
+	task.Tags[strings.TrimPrefix(name, "@")] = tagValue
+	return nil
+}
+
+// @project 的内容可能如：
+// @project(BUGFIX.BCS)
+var parseProject = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	projectContent := strings.TrimSpace(value)
 	if len(projectContent) == 0 {
-		return fmt.Errorf("project content cannot be empty")
+		return TagValue{}, fmt.Errorf("project content cannot be empty")
 	}
 
 	// split by "."
 	projectParts := strings.Split(projectContent, ".")
-	if len(projectParts) == 0 {
-		return fmt.Errorf("project content must contain at least one dot")
-	}
-
 	task.Category = projectParts[0]
 	if len(projectParts) > 1 {
 		task.Project = strings.Join(projectParts[1:], ".")
 	}
 
-	logger.Debug("解析 project tag %s 成功: 分类=%s, 项目=%s", tagContent, task.Category, task.Project)
-	return nil
+	logger.Debug("解析 project tag %s 成功: 分类=%s, 项目=%s", value, task.Category, task.Project)
+	return TagValue{Kind: TagValueString, Raw: value, Text: projectContent}, nil
+})
+
+// e.g. @created(24-11-22 14:58)
+var parseCreated = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	return parseTimeTag("created", value)
 })
 
 // e.g. @started(24-11-22 14:58)
-var parseStarted = tagParser(func(tagContent string, task *TaskInfo) error {
-	tagContent = strings.TrimPrefix(tagContent, "@started")
+var parseStarted = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	tagValue, err := parseTimeTag("started", value)
+	if err != nil {
+		return TagValue{}, err
+	}
 
-	// 校验 tagContent 格式
-	if len(tagContent) == 0 {
-		return fmt.Errorf("started tag content cannot be empty")
+	task.StartDate = tagValue.Time
+	logger.Debug("解析 started tag %s 成功: %v", value, tagValue.Time)
+	return tagValue, nil
+})
+
+// e.g. @done(24-11-23 10:00)
+var parseDone = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	tagValue, err := parseTimeTag("done", value)
+	if err != nil {
+		return TagValue{}, err
 	}
 
-	// 检查是否以括号包裹
-	if !strings.HasPrefix(tagContent, "(") || !strings.HasSuffix(tagContent, ")") {
-		return fmt.Errorf("started tag content must be wrapped in parentheses")
+	task.EndDate = tagValue.Time
+	logger.Debug("解析 done tag %s 成功: %v", value, tagValue.Time)
+	return tagValue, nil
+})
+
+// @cancelled 的内容可以省略，如 @cancelled、@cancelled(24-11-23 10:00)
+var parseCancelled = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	task.Status = TaskStatusCancel
+	if strings.TrimSpace(value) == "" {
+		return TagValue{Kind: TagValueNone}, nil
 	}
+	return parseTimeTag("cancelled", value)
+})
 
-	// 提取括号中的内容
-	timeContent := strings.TrimSpace(tagContent[1 : len(tagContent)-1])
-	if len(timeContent) == 0 {
-		return fmt.Errorf("started time content cannot be empty")
+// e.g. @lasted(1d8h)
+var parseLasted = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	return parseDurationTag("lasted", value)
+})
+
+// e.g. @est(2h30m)
+var parseEst = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	return parseDurationTag("est", value)
+})
+
+// @due 的内容可以只有日期，如 @due(24-11-30)、@due(24-11-30 18:00)
+var parseDue = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	content := strings.TrimSpace(value)
+	if content == "" {
+		return TagValue{}, fmt.Errorf("due time content cannot be empty")
 	}
 
-	// 解析时间
-	startTime, err := parseDatetime(timeContent)
+	due, err := parseDatetime(content)
 	if err != nil {
-		return fmt.Errorf("parse started time failed: %w", err)
+		if due, err = parseDatetime(content + " 00:00"); err != nil {
+			return TagValue{}, fmt.Errorf("parse due time failed: %w", err)
+		}
 	}
+	return TagValue{Kind: TagValueTime, Raw: value, Time: due}, nil
+})
 
-	task.StartDate = startTime
-	logger.Debug("解析 started tag %s 成功: %v", tagContent, startTime)
-	return nil
+// @priority 的内容为整数，数字越小优先级越高，如 @priority(1)
+var parsePriority = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	priority, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || priority < 0 {
+		return TagValue{}, fmt.Errorf("invalid priority %q, expect a non-negative integer", value)
+	}
+	return TagValue{Kind: TagValueNumber, Raw: value, Number: float64(priority)}, nil
 })
 
-var parseDone = tagParser(func(tagContent string, task *TaskInfo) error {
-	tagContent = strings.TrimPrefix(tagContent, "@done")
+// @progress 的内容可能如：
+// @progress(100)
+var parseProgress = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	content := strings.TrimSpace(value)
+	if len(content) == 0 {
+		return TagValue{}, fmt.Errorf("progress content cannot be empty")
+	}
 
-	// 校验 tagContent 格式
-	if len(tagContent) == 0 {
-		return fmt.Errorf("done tag content cannot be empty")
+	// 只保留数字部分
+	var buffer strings.Builder
+	for _, c := range content {
+		if c >= '0' && c <= '9' {
+			buffer.WriteRune(c)
+		}
 	}
+	content = buffer.String()
 
-	// 检查是否以括号包裹
-	if !strings.HasPrefix(tagContent, "(") || !strings.HasSuffix(tagContent, ")") {
-		return fmt.Errorf("done tag content must be wrapped in parentheses")
+	// 解析百分比
+	percent, err := strconv.Atoi(content)
+	if err != nil || percent < 0 || percent > 100 {
+		return TagValue{}, fmt.Errorf("invalid progress: %s", value)
 	}
 
-	// 提取括号中的内容
-	timeContent := strings.TrimSpace(tagContent[1 : len(tagContent)-1])
-	if len(timeContent) == 0 {
-		return fmt.Errorf("done time content cannot be empty")
+	logger.Debug("解析 progress tag %s 成功: %d", value, percent)
+	task.Percent = percent
+	return TagValue{Kind: TagValueNumber, Raw: value, Number: float64(percent)}, nil
+})
+
+// parseAny 按内容推断未注册的标签的值：时间、时长、数字、逗号分隔的列表或字符串
+var parseAny = TagParser(func(value string, task *TaskInfo) (TagValue, error) {
+	return InferTagValue(value), nil
+})
+
+func parseTimeTag(name, value string) (TagValue, error) {
+	content := strings.TrimSpace(value)
+	if len(content) == 0 {
+		return TagValue{}, fmt.Errorf("%s time content cannot be empty", name)
 	}
 
-	// 解析时间
-	endTime, err := parseDatetime(timeContent)
+	t, err := parseDatetime(content)
 	if err != nil {
-		return fmt.Errorf("parse done time failed: %w", err)
+		return TagValue{}, fmt.Errorf("parse %s time failed: %w", name, err)
 	}
+	return TagValue{Kind: TagValueTime, Raw: value, Time: t}, nil
+}
 
-	task.EndDate = endTime
-	logger.Debug("解析 done tag %s 成功: %v", tagContent, endTime)
+func parseDurationTag(name, value string) (TagValue, error) {
+	content := strings.TrimSpace(value)
+	if len(content) == 0 {
+		return TagValue{}, fmt.Errorf("%s content cannot be empty", name)
+	}
 
-	return nil
-})
+	d, err := ParseDuration(content)
+	if err != nil {
+		return TagValue{}, fmt.Errorf("parse %s failed: %w", name, err)
+	}
+	return TagValue{Kind: TagValueDuration, Raw: value, Duration: d}, nil
+}
 
 // content e.g. 24-11-22 14:58
 func parseDatetime(content string) (*TaskTime, error) {
@@ -313,27 +510,27 @@ func parseDatetime(content string) (*TaskTime, error) {
 
 	year, err := strconv.Atoi(dateParts[0])
 	if err != nil {
-		return nil, fmt.Errorf("invalid year: %w", err)
+		return nil, fmt.Errorf("invalid year: %q", dateParts[0])
 	}
 
 	month, err := strconv.Atoi(dateParts[1])
 	if err != nil || month < 1 || month > 12 {
-		return nil, fmt.Errorf("invalid month: %w", err)
+		return nil, fmt.Errorf("invalid month: %q", dateParts[1])
 	}
 
 	day, err := strconv.Atoi(dateParts[2])
 	if err != nil || day < 1 || day > 31 {
-		return nil, fmt.Errorf("invalid day: %w", err)
+		return nil, fmt.Errorf("invalid day: %q", dateParts[2])
 	}
 
 	hour, err := strconv.Atoi(timeParts[0])
 	if err != nil || hour < 0 || hour > 23 {
-		return nil, fmt.Errorf("invalid hour: %w", err)
+		return nil, fmt.Errorf("invalid hour: %q", timeParts[0])
 	}
 
 	min, err := strconv.Atoi(timeParts[1])
 	if err != nil || min < 0 || min > 59 {
-		return nil, fmt.Errorf("invalid minute: %w", err)
+		return nil, fmt.Errorf("invalid minute: %q", timeParts[1])
 	}
 
 	return &TaskTime{
@@ -344,49 +541,3 @@ func parseDatetime(content string) (*TaskTime, error) {
 		Min:   min,
 	}, nil
 }
-
-func parseCancelled(tagContent string, task *TaskInfo) error {
-	task.Status = TaskStatusCancel
-	return nil
-}
-
-// @progress 的内容可能如：
-// @progress(100)
-var parseProgress = tagParser(func(tagContent string, task *TaskInfo) error {
-	tagContent = strings.TrimPrefix(tagContent, "@progress")
-
-	// 校验 tagContent 格式
-	if len(tagContent) == 0 {
-		return fmt.Errorf("progress tag content cannot be empty")
-	}
-
-	// 检查是否以括号包裹
-	if !strings.HasPrefix(tagContent, "(") || !strings.HasSuffix(tagContent, ")") {
-		return fmt.Errorf("progress tag content must be wrapped in parentheses")
-	}
-
-	// 提取括号中的内容
-	timeContent := strings.TrimSpace(tagContent[1 : len(tagContent)-1])
-	if len(timeContent) == 0 {
-		return fmt.Errorf("progress content cannot be empty")
-	}
-
-	// 只保留数字部分
-	var buffer strings.Builder
-	for _, c := range timeContent {
-		if c >= '0' && c <= '9' {
-			buffer.WriteRune(c)
-		}
-	}
-	timeContent = buffer.String()
-
-	// 解析百分比
-	percent, err := strconv.Atoi(timeContent)
-	if err != nil || percent < 0 || percent > 100 {
-		return fmt.Errorf("invalid progress: %w", err)
-	}
-
-	logger.Debug("解析 progress tag %s 成功: %d", tagContent, percent)
-	task.Percent = percent
-	return nil
-})
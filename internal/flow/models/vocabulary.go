@@ -0,0 +1,145 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+// StatusNames 配置中使用的状态名称
+var StatusNames = map[string]TaskStatus{
+	"in_progress": TaskStatusInProgress,
+	"done":        TaskStatusDone,
+	"cancelled":   TaskStatusCancel,
+}
+
+// 内置的符号和标签，Configure 在此基础上合并配置中的符号和标签
+var (
+	builtinSymbolSet      = maps.Clone(SymbolSet)
+	builtinDefaultSymbols = maps.Clone(DefaultSymbols)
+	builtinTagSet         = maps.Clone(TagSet)
+)
+
+// Vocabulary 配置中额外的状态符号和标签
+type Vocabulary struct {
+	Symbols         map[TaskStatus][]string // 每个状态额外的符号，如 ◻、✅
+	Preferred       map[TaskStatus]string   // 写入任务时每个状态使用的符号，不在符号集中时自动加入
+	Tags            []string                // 额外的标签名称，可以省略 @，如 review
+	WarnUnknownTags bool                    // 解析时遇到不在 TagSet 中的标签是否给出警告
+}
+
+// WarnUnknownTags 解析时遇到不在 TagSet 中的标签是否给出警告，默认不警告，任意的 @key(value) 标签都是合法的
+var WarnUnknownTags bool
+
+// Configure 把 v 合并到内置的 SymbolSet、DefaultSymbols 和 TagSet 中，重复调用时以最后一次为准
+// 符号或标签有问题时返回错误，此时符号和标签保持内置的值
+func Configure(v Vocabulary) error {
+	symbols, defaults, tags, symbolErrs, tagErrs := v.merge()
+	if errs := append(symbolErrs, tagErrs...); len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, err := range errs {
+			messages[i] = err.Error()
+		}
+		return fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+
+	SymbolSet, DefaultSymbols, TagSet = symbols, defaults, tags
+	WarnUnknownTags = v.WarnUnknownTags
+	return nil
+}
+
+// Check 按 Configure 的规则检查 v，分别返回符号和标签的问题，不修改内置的符号和标签
+// 校验配置时使用，这样配置中的问题可以在加载配置时报告，而不是等到 Configure 时才失败
+func (v Vocabulary) Check() (symbolErrs, tagErrs []error) {
+	_, _, _, symbolErrs, tagErrs = v.merge()
+	return symbolErrs, tagErrs
+}
+
+// merge 把 v 合并到内置符号和标签的副本中，符号不能已被内置的或其它状态使用
+func (v Vocabulary) merge() (symbols map[string]TaskStatus, defaults map[TaskStatus]string, tags map[string]TagType, symbolErrs, tagErrs []error) {
+	symbols = maps.Clone(builtinSymbolSet)
+	defaults = maps.Clone(builtinDefaultSymbols)
+	tags = maps.Clone(builtinTagSet)
+
+	addSymbol := func(status TaskStatus, symbol string) {
+		if err := validSymbol(symbol); err != nil {
+			symbolErrs = append(symbolErrs, fmt.Errorf("%s的%w", status, err))
+			return
+		}
+		if existing, ok := symbols[symbol]; ok && existing != status {
+			symbolErrs = append(symbolErrs, fmt.Errorf("%s的符号 %q 已用于状态 %s", status, symbol, existing))
+			return
+		}
+		symbols[symbol] = status
+	}
+
+	for _, status := range sortedStatuses(v.Symbols) {
+		for _, symbol := range v.Symbols[status] {
+			addSymbol(status, symbol)
+		}
+	}
+	for _, status := range sortedStatuses(v.Preferred) {
+		symbol := v.Preferred[status]
+		if symbol == "" {
+			continue
+		}
+		addSymbol(status, symbol)
+		defaults[status] = symbol
+	}
+
+	for _, name := range v.Tags {
+		name = strings.TrimSpace(name)
+		if !strings.HasPrefix(name, "@") {
+			name = "@" + name
+		}
+		if err := validTagName(name); err != nil {
+			tagErrs = append(tagErrs, err)
+			continue
+		}
+		if _, ok := tags[name]; !ok {
+			tags[name] = TagType(name)
+		}
+	}
+
+	return symbols, defaults, tags, symbolErrs, tagErrs
+}
+
+// validSymbol 符号不能为空，也不能包含空白和 @
+func validSymbol(symbol string) error {
+	if symbol == "" {
+		return fmt.Errorf("符号不能为空")
+	}
+	if strings.ContainsAny(symbol, " \t@") {
+		return fmt.Errorf("符号 %q 不能包含空白或 @", symbol)
+	}
+	return nil
+}
+
+// validTagName 标签名称为 @ 加字母、数字、_ 或 -
+func validTagName(name string) error {
+	rest := strings.TrimPrefix(name, "@")
+	if rest == "" {
+		return fmt.Errorf("标签名称不能为空")
+	}
+	for _, r := range rest {
+		if r != '_' && r != '-' && !isLetterOrDigit(r) {
+			return fmt.Errorf("标签名称 %q 无效，只能包含字母、数字、_ 和 -", name)
+		}
+	}
+	return nil
+}
+
+func isLetterOrDigit(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r > 0x7f
+}
+
+// sortedStatuses 按状态排序，保证报错的顺序稳定
+func sortedStatuses[V any](m map[TaskStatus]V) []TaskStatus {
+	statuses := make([]TaskStatus, 0, len(m))
+	for status := range m {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	return statuses
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	defer Configure(Vocabulary{})
+
+	require.NoError(t, Configure(Vocabulary{
+		Symbols:   map[TaskStatus][]string{TaskStatusInProgress: {"◻"}},
+		Preferred: map[TaskStatus]string{TaskStatusDone: "✅"},
+		Tags:      []string{"review", "@blocked"},
+	}))
+
+	assert.Equal(t, TaskStatusInProgress, SymbolSet["◻"])
+	assert.Equal(t, TaskStatusDone, SymbolSet["✅"])
+	assert.Equal(t, TaskStatusDone, SymbolSet["✔"])
+	assert.Equal(t, "✅", DefaultSymbols[TaskStatusDone])
+	assert.Equal(t, "☐", DefaultSymbols[TaskStatusInProgress])
+	assert.Contains(t, TagSet, "@review")
+	assert.Contains(t, TagSet, "@blocked")
+
+	// 自定义的符号修改状态时使用配置的符号
+	assert.Equal(t, "✅", StatusSymbol(TaskStatusDone, "◻"))
+	assert.Equal(t, "[x]", StatusSymbol(TaskStatusDone, "[ ]"))
+
+	// 再次调用时以最后一次为准
+	require.NoError(t, Configure(Vocabulary{}))
+	assert.NotContains(t, SymbolSet, "◻")
+	assert.NotContains(t, TagSet, "@review")
+	assert.Equal(t, "✔", DefaultSymbols[TaskStatusDone])
+}
+
+func TestConfigureConflict(t *testing.T) {
+	defer Configure(Vocabulary{})
+
+	err := Configure(Vocabulary{
+		Symbols:   map[TaskStatus][]string{TaskStatusDone: {"x", ""}},
+		Preferred: map[TaskStatus]string{TaskStatusCancel: "✔"},
+		Tags:      []string{"bad tag"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `符号 "x" 已用于状态 已取消`)
+	assert.Contains(t, err.Error(), "符号不能为空")
+	assert.Contains(t, err.Error(), `符号 "✔" 已用于状态 已完成`)
+	assert.Contains(t, err.Error(), `标签名称 "@bad tag" 无效`)
+
+	// 出错时保持内置的符号
+	assert.Equal(t, TaskStatusCancel, SymbolSet["x"])
+	assert.Equal(t, "✘", DefaultSymbols[TaskStatusCancel])
+}
@@ -0,0 +1,52 @@
+package flow
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"mycmd/internal/flow/backup"
+	"mycmd/pkg/config"
+	"mycmd/pkg/fileutil"
+	"mycmd/pkg/logger"
+)
+
+// todoFilePath 返回指定类型的 todo 文件路径: <todo_dir>/<type>/<type>.todo
+func todoFilePath(todoType string) string {
+	return filepath.Join(config.Get().Flow.TodoDir, todoType, fmt.Sprintf("%s.todo", todoType))
+}
+
+// newBackupManager 根据配置创建 todo 文件夹的备份管理器
+func newBackupManager() *backup.Manager {
+	cfg := config.Get().Flow
+	maxAge := time.Duration(cfg.Backup.MaxDays) * 24 * time.Hour
+	return backup.NewManager(cfg.TodoDir, cfg.Backup.Keep, maxAge)
+}
+
+// writeFile 加锁后原子地写入文件，覆盖已存在的文件前先备份到 todo 文件夹的 .backup 目录
+func writeFile(path string, data []byte) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := newBackupManager().Snapshot(path, data); err != nil {
+		return fmt.Errorf("备份文件失败: %w", err)
+	}
+	return fileutil.WriteFile(path, data, 0644)
+}
+
+// lockFile 获取文件锁，返回释放锁的函数
+// 修改 todo 文件的命令在读取前加锁，保证 读取-修改-写入 的过程中文件不会被其它 mycmd 进程修改
+func lockFile(path string) (func(), error) {
+	l, err := fileutil.LockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := l.Unlock(); err != nil {
+			logger.Warning("%v", err)
+		}
+	}, nil
+}
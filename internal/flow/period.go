@@ -0,0 +1,115 @@
+package flow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mycmd/pkg/config"
+)
+
+// periodNames 支持的时间段名称，用于帮助信息
+const periodNames = "today、yesterday、this-week、last-week、this-month、last-month、" +
+	"this-quarter、last-quarter、this-year、last-year、Q3、2024-Q3、2024-W47、2024"
+
+var (
+	quarterPattern = regexp.MustCompile(`^(?:(\d{4})-)?[qQ]([1-4])$`)
+	isoWeekPattern = regexp.MustCompile(`^(\d{4})-[wW](\d{1,2})$`)
+	yearPattern    = regexp.MustCompile(`^\d{4}$`)
+)
+
+// resolvePeriod 将时间段名称解析为日期范围
+// now 为当前时间，weekStart 为每周的第一天，year 为 Q3 等不带年份的季度使用的年份
+func resolvePeriod(period string, now time.Time, weekStart time.Weekday, year int) (dateRange, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	thisWeek := today.AddDate(0, 0, -((int(today.Weekday()) - int(weekStart) + 7) % 7))
+	thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.Local)
+	thisQuarter := time.Date(today.Year(), (today.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.Local)
+	thisYear := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.Local)
+
+	p := strings.ToLower(strings.TrimSpace(period))
+	switch p {
+	case "today":
+		return dateRange{Start: today, End: today}, nil
+	case "yesterday":
+		yesterday := today.AddDate(0, 0, -1)
+		return dateRange{Start: yesterday, End: yesterday}, nil
+	case "this-week":
+		return newPeriodRange(thisWeek, 0, 0, 7), nil
+	case "last-week":
+		return newPeriodRange(thisWeek.AddDate(0, 0, -7), 0, 0, 7), nil
+	case "this-month":
+		return newPeriodRange(thisMonth, 0, 1, 0), nil
+	case "last-month":
+		return newPeriodRange(thisMonth.AddDate(0, -1, 0), 0, 1, 0), nil
+	case "this-quarter":
+		return newPeriodRange(thisQuarter, 0, 3, 0), nil
+	case "last-quarter":
+		return newPeriodRange(thisQuarter.AddDate(0, -3, 0), 0, 3, 0), nil
+	case "this-year":
+		return newPeriodRange(thisYear, 1, 0, 0), nil
+	case "last-year":
+		return newPeriodRange(thisYear.AddDate(-1, 0, 0), 1, 0, 0), nil
+	}
+
+	if m := quarterPattern.FindStringSubmatch(p); m != nil {
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		quarter, _ := strconv.Atoi(m[2])
+		start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.Local)
+		return newPeriodRange(start, 0, 3, 0), nil
+	}
+
+	if m := isoWeekPattern.FindStringSubmatch(p); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		start, err := isoWeekStart(year, week)
+		if err != nil {
+			return dateRange{}, err
+		}
+		return newPeriodRange(start, 0, 0, 7), nil
+	}
+
+	if yearPattern.MatchString(p) {
+		year, _ := strconv.Atoi(p)
+		return newPeriodRange(time.Date(year, 1, 1, 0, 0, 0, 0, time.Local), 1, 0, 0), nil
+	}
+
+	return dateRange{}, fmt.Errorf("无效的时间段: %s，支持: %s", period, periodNames)
+}
+
+// newPeriodRange 返回从 start 开始，长度为指定年、月、日的日期范围
+func newPeriodRange(start time.Time, years, months, days int) dateRange {
+	return dateRange{Start: start, End: start.AddDate(years, months, days-1)}
+}
+
+// isoWeekStart 返回 ISO 8601 周的第一天（周一）
+// 每年的 1 月 4 日总是在第 1 周
+func isoWeekStart(year, week int) (time.Time, error) {
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.Local)
+	week1 := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	start := week1.AddDate(0, 0, (week-1)*7)
+
+	if y, w := start.ISOWeek(); y != year || w != week {
+		return time.Time{}, fmt.Errorf("无效的 ISO 周: %d-W%02d", year, week)
+	}
+	return start, nil
+}
+
+// resolveWeekStart 返回配置 flow.week_start 指定的每周第一天，默认为周一
+func resolveWeekStart() (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(config.Get().Flow.WeekStart))
+	if name == "" {
+		return time.Monday, nil
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.ToLower(day.String()) == name {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("无效的配置 flow.week_start: %s，应为 monday、sunday 等", name)
+}
@@ -0,0 +1,54 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePeriod(t *testing.T) {
+	// 2024-11-27 是周三
+	now := time.Date(2024, 11, 27, 15, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		period    string
+		weekStart time.Weekday
+		want      string
+		wantErr   bool
+	}{
+		{name: "今天", period: "today", want: "2024-11-27~2024-11-27"},
+		{name: "昨天", period: "yesterday", want: "2024-11-26~2024-11-26"},
+		{name: "本周（周一开始）", period: "this-week", weekStart: time.Monday, want: "2024-11-25~2024-12-01"},
+		{name: "上周（周一开始）", period: "last-week", weekStart: time.Monday, want: "2024-11-18~2024-11-24"},
+		{name: "上周（周日开始）", period: "Last-Week", weekStart: time.Sunday, want: "2024-11-17~2024-11-23"},
+		{name: "本周（周三开始，当天为第一天）", period: "this-week", weekStart: time.Wednesday, want: "2024-11-27~2024-12-03"},
+		{name: "本月", period: "this-month", want: "2024-11-01~2024-11-30"},
+		{name: "上月", period: "last-month", want: "2024-10-01~2024-10-31"},
+		{name: "本季度", period: "this-quarter", want: "2024-10-01~2024-12-31"},
+		{name: "上季度", period: "last-quarter", want: "2024-07-01~2024-09-30"},
+		{name: "今年", period: "this-year", want: "2024-01-01~2024-12-31"},
+		{name: "去年", period: "last-year", want: "2023-01-01~2023-12-31"},
+		{name: "季度使用指定年份", period: "Q3", want: "2023-07-01~2023-09-30"},
+		{name: "带年份的季度", period: "2022-q1", want: "2022-01-01~2022-03-31"},
+		{name: "ISO 周", period: "2024-W47", want: "2024-11-18~2024-11-24"},
+		{name: "跨年的 ISO 周", period: "2025-W01", want: "2024-12-30~2025-01-05"},
+		{name: "第 53 周", period: "2020-W53", want: "2020-12-28~2021-01-03"},
+		{name: "不存在的 ISO 周", period: "2024-W53", wantErr: true},
+		{name: "年份", period: "2024", want: "2024-01-01~2024-12-31"},
+		{name: "无效的时间段", period: "next-week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePeriod(tt.period, now, tt.weekStart, 2023)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
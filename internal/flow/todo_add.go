@@ -0,0 +1,108 @@
+package flow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mycmd/internal/flow/models"
+	"mycmd/internal/flow/todofile"
+	"mycmd/pkg/logger"
+)
+
+type todoAddOptions struct {
+	todoType string
+	project  string
+}
+
+func NewTodoAddCmd() *cobra.Command {
+	opts := &todoAddOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "todo-add <任务名称>",
+		Short: "添加任务到指定分类和项目下",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(strings.Join(args, " "))
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.todoType, "type", "", "todo 类型 (work)")
+	cmd.Flags().StringVar(&opts.project, "project", "", "任务所属的分类和项目，格式：CATEGORY.PROJECT 或 CATEGORY")
+	cmd.MarkFlagRequired("type")
+	cmd.MarkFlagRequired("project")
+
+	return cmd
+}
+
+func (o *todoAddOptions) run(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("任务名称不能为空")
+	}
+
+	category, project, _ := strings.Cut(o.project, ".")
+	category, project = strings.TrimSpace(category), strings.TrimSpace(project)
+	if category == "" {
+		return fmt.Errorf("项目格式错误，应为: CATEGORY.PROJECT 或 CATEGORY")
+	}
+
+	todoFile := todoFilePath(o.todoType)
+	unlock, err := lockFile(todoFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	doc, err := parseTodoFile(todoFile)
+	if err != nil {
+		return err
+	}
+
+	node := o.addTask(doc, category, project, name, time.Now())
+
+	if err := writeFile(todoFile, doc.Bytes()); err != nil {
+		return fmt.Errorf("写入 todo 文件失败: %w", err)
+	}
+
+	logger.Success("已添加任务(第 %d 行): %s", node.Line, node.Text)
+	return nil
+}
+
+// addTask 将任务插入到分类和项目的末尾，分类或项目不存在时自动创建
+func (o *todoAddOptions) addTask(doc *todofile.Document, category, project, name string, now time.Time) *todofile.Node {
+	text := fmt.Sprintf("%s %s @created(%s)",
+		models.DefaultSymbols[models.TaskStatusInProgress], name, models.NewTaskTimeFromTime(now))
+	return doc.AppendChild(ensureHeading(doc, category, project), text)
+}
+
+// ensureHeading 查找分类和项目对应的标题行，不存在时在文件中创建
+// 多级项目使用 . 分隔，如 BCS.API
+func ensureHeading(doc *todofile.Document, category, project string) *todofile.Node {
+	heading := doc.FindCategory(category)
+	if heading == nil {
+		logger.Info("分类 %s 不存在，已自动创建", category)
+		heading = doc.AppendChild(nil, category+":")
+	}
+	if project == "" {
+		return heading
+	}
+
+	for _, name := range strings.Split(project, ".") {
+		var child *todofile.Node
+		for _, c := range heading.Children {
+			if c.Kind == todofile.NodeProject && c.Title == name {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			logger.Info("项目 %s.%s 不存在，已自动创建", category, project)
+			child = doc.AppendChild(heading, name+":")
+		}
+		heading = child
+	}
+	return heading
+}
@@ -0,0 +1,66 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"mycmd/internal/flow/todofile"
+)
+
+func TestTodoAddOptions_addTask(t *testing.T) {
+	content := "工作:\n" +
+		"\tBCS:\n" +
+		"\t\t☐ 已有任务\n" +
+		"\n" +
+		"\tDUAL:\n" +
+		"学习:"
+
+	now := time.Date(2024, 11, 22, 9, 5, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		category string
+		project  string
+		expected string
+	}{
+		{
+			name:     "插入到已有项目末尾",
+			category: "工作",
+			project:  "BCS",
+			expected: "工作:\n\tBCS:\n\t\t☐ 已有任务\n\t\t☐ 新任务 @created(24-11-22 09:05)\n\n\tDUAL:\n学习:",
+		},
+		{
+			name:     "插入到空项目",
+			category: "工作",
+			project:  "DUAL",
+			expected: "工作:\n\tBCS:\n\t\t☐ 已有任务\n\n\tDUAL:\n\t\t☐ 新任务 @created(24-11-22 09:05)\n学习:",
+		},
+		{
+			name:     "项目不存在时创建项目",
+			category: "学习",
+			project:  "GO",
+			expected: "工作:\n\tBCS:\n\t\t☐ 已有任务\n\n\tDUAL:\n学习:\n\tGO:\n\t\t☐ 新任务 @created(24-11-22 09:05)",
+		},
+		{
+			name:     "分类不存在时创建分类",
+			category: "生活",
+			expected: "工作:\n\tBCS:\n\t\t☐ 已有任务\n\n\tDUAL:\n学习:\n生活:\n\t☐ 新任务 @created(24-11-22 09:05)",
+		},
+	}
+
+	opts := &todoAddOptions{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := todofile.Parse([]byte(content))
+			node := opts.addTask(doc, tt.category, tt.project, "新任务", now)
+
+			assert.Equal(t, tt.expected, doc.String())
+			assert.Equal(t, tt.category, node.Task.Category)
+			assert.Equal(t, tt.project, node.Task.Project)
+			assert.Equal(t, node, doc.Nodes[node.Line-1])
+		})
+	}
+}
@@ -1,27 +1,39 @@
 package flow
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"mycmd/internal/flow/models"
+	"mycmd/internal/flow/todofile"
 	"mycmd/pkg/config"
 	"mycmd/pkg/logger"
 )
 
 type todoArchiveOptions struct {
-	todoType string
-	date     string
+	todoType  string
+	date      string
+	period    string
+	year      int
+	format    string
+	template  string
+	prune     bool
+	pruneMode string
+	dryRun    bool
 }
 
+// --prune-mode 的取值
+const (
+	pruneModeRemove = "remove" // 直接删除
+	pruneModeMove   = "move"   // 移动到 Archive: 分类下
+)
+
 func NewTodoArchiveCmd() *cobra.Command {
 	opts := &todoArchiveOptions{}
 
@@ -34,290 +46,305 @@ func NewTodoArchiveCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&opts.todoType, "type", "", "todo 类型 (work)")
-	cmd.Flags().StringVar(&opts.date, "date", "", "归档日期范围，格式：MM/DD,MM/DD")
+	cmd.Flags().StringVar(&opts.date, "date", "", "归档日期范围，格式："+dateRangeFormat)
+	cmd.Flags().StringVar(&opts.period, "period", "", "归档时间段，如："+periodNames)
+	cmd.Flags().IntVar(&opts.year, "year", 0, "MM/DD 格式日期和季度的年份，默认使用配置 flow.current_year 或今年")
+	cmd.Flags().StringVar(&opts.format, "format", "",
+		"归档格式 ("+strings.Join(archiveFormatNames(), "/")+")，默认使用配置 flow.archive_template 或 "+defaultArchiveFormat)
+	cmd.Flags().StringVar(&opts.template, "template", "", "归档模板文件路径 (text/template 语法)")
+	cmd.Flags().BoolVar(&opts.prune, "prune", false, "归档后从 todo 文件中清理已归档的已完成和已取消任务，保留进行中的任务")
+	cmd.Flags().StringVar(&opts.pruneMode, "prune-mode", pruneModeRemove,
+		"清理方式: remove 直接删除, move 移动到 todo 文件末尾的 Archive: 分类下")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "只预览 --prune 对 todo 文件的修改，不写入任何文件")
 	cmd.MarkFlagRequired("type")
-	cmd.MarkFlagRequired("date")
+	cmd.MarkFlagsOneRequired("date", "period")
+	cmd.MarkFlagsMutuallyExclusive("date", "period")
+	cmd.MarkFlagsMutuallyExclusive("format", "template")
 
 	return cmd
 }
 
 func (o *todoArchiveOptions) run() error {
-	dates := strings.Split(o.date, ",")
-	if len(dates) != 2 {
-		return fmt.Errorf("日期格式错误，应为: MM/DD,MM/DD")
+	if o.pruneMode != pruneModeRemove && o.pruneMode != pruneModeMove {
+		return fmt.Errorf("无效的清理方式: %s，应为: %s 或 %s", o.pruneMode, pruneModeRemove, pruneModeMove)
+	}
+	if o.dryRun && !o.prune {
+		return fmt.Errorf("--dry-run 需要与 --prune 一起使用")
+	}
+
+	formatter, err := o.archiveFormatter()
+	if err != nil {
+		return err
 	}
-	startDate, endDate := dates[0], dates[1]
 
-	archiveStartDate := strings.ReplaceAll(startDate, "/", "-")
-	archiveEndDate := strings.ReplaceAll(endDate, "/", "-")
+	r, err := o.dateRange()
+	if err != nil {
+		return err
+	}
 
 	todoDir := config.Get().Flow.TodoDir
-	todoFile := filepath.Join(todoDir, o.todoType, fmt.Sprintf("%s.todo", o.todoType))
-	archiveFile := filepath.Join(todoDir, o.todoType, fmt.Sprintf("%s(%s~%s).archive",
-		o.todoType, archiveStartDate, archiveEndDate))
+	todoFile := todoFilePath(o.todoType)
+	archiveFile := filepath.Join(todoDir, o.todoType, fmt.Sprintf("%s(%s)%s", o.todoType, r, formatter.Extension()))
 
 	if err := os.MkdirAll(filepath.Dir(archiveFile), 0755); err != nil {
 		return fmt.Errorf("创建目录失败: %w", err)
 	}
 
+	if o.prune {
+		unlock, err := lockFile(todoFile)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
 	// 处理 todo 文件
-	tasks, err := o.processTodoFile(todoFile, startDate, endDate)
+	doc, nodes, err := o.processTodoFile(todoFile, r)
 	if err != nil {
 		return err
 	}
 
-	// 生成归档内容
-	content := o.generateArchiveContent(tasks)
-
-	// 写入归档文件
-	if err := os.WriteFile(archiveFile, []byte(content), 0644); err != nil {
-		return fmt.Errorf("写入归档文件失败: %w", err)
+	tasks := make([]models.TaskInfo, 0, len(nodes))
+	for _, node := range nodes {
+		tasks = append(tasks, *node.Task)
 	}
 
-	logger.Success("已成功创建归档文件: %s", archiveFile)
-	return nil
-}
-
-func (o *todoArchiveOptions) processTodoFile(todoFile string, startDate, endDate string) ([]models.TaskInfo, error) {
-	logger.Debug("开始处理 todo 文件: %s", todoFile)
-	logger.Info("归档日期范围: %s ~ %s", startDate, endDate)
+	archive := &Archive{
+		Type:  o.todoType,
+		Start: r.Start,
+		End:   r.End,
+		Tasks: tasks,
+	}
+	printArchiveSummary(archive)
 
-	file, err := os.Open(todoFile)
+	// 生成归档内容
+	content, err := formatter.Format(archive)
 	if err != nil {
-		return nil, fmt.Errorf("打开 todo 文件失败: %w", err)
+		return fmt.Errorf("生成归档内容失败: %w", err)
 	}
-	defer file.Close()
 
-	var tasks []models.TaskInfo
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
+	pruned := 0
+	if o.prune {
+		before := doc.Bytes()
+		pruned = o.pruneTasks(doc, nodes)
 
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		line = strings.TrimSpace(line)
-
-		task := o.parseTaskLine(line)
-		if task == nil {
-			continue
-		}
-
-		if o.isDateInRange(startDate, endDate, task.StartDate, task.EndDate) {
-			logger.Success("找到符合条件的任务: %s", task.Name)
-			tasks = append(tasks, *task)
-		} else {
-			logger.Warning("任务 %s 不在日期范围内", task.Name)
+		if o.dryRun {
+			diff, err := unifiedDiff(todoFile, before, doc.Bytes())
+			if err != nil {
+				return err
+			}
+			printDiff(diff)
+			logger.Info("dry-run: 将清理 %d 个已归档的任务，未写入任何文件", pruned)
+			return nil
 		}
 	}
 
-	logger.Debug("\n总结: 共处理 %d 行，找到 %d 个符合条件的任务", lineNum, len(tasks))
-	return tasks, scanner.Err()
-}
-
-// ✔ mock-duale @done(24-11-21 15:41) @project(REFACTOR.DUALENGINE)
-// 当第一个 @ 出现之后，后面所有内容都是 tag，应该拆分每个 @ 内容，而不是按照空格拆分
-func (o *todoArchiveOptions) parseTaskLine(line string) *models.TaskInfo {
-	task := &models.TaskInfo{}
-
-	line = strings.TrimSpace(line)
-	if line == "" {
-		return nil
+	// 写入归档文件
+	if err := writeFile(archiveFile, content); err != nil {
+		return fmt.Errorf("写入归档文件失败: %w", err)
 	}
+	logger.Success("已成功创建归档文件: %s", archiveFile)
 
-	// 检查每个已知的符号
-	var matchedSymbol string
-	var status models.TaskStatus
-	var startedBySymbol bool
-
-	// 先尝试匹配最长的符号
-	for symbol := range models.SymbolSet {
-		if strings.HasPrefix(line, symbol) {
-			if len(symbol) > len(matchedSymbol) {
-				matchedSymbol = symbol
-				status, startedBySymbol = models.SymbolSet[symbol]
-			}
+	if pruned > 0 {
+		if err := writeFile(todoFile, doc.Bytes()); err != nil {
+			return fmt.Errorf("写入 todo 文件失败: %w", err)
 		}
+		logger.Success("已从 todo 文件中清理 %d 个已归档的任务", pruned)
 	}
+	return nil
+}
 
-	if !startedBySymbol {
-		return nil
-	}
+// pruneTasks 清理已归档的已完成和已取消任务，返回清理的任务数量
+// 子任务随父任务一起清理；包含进行中子任务的任务不清理；已经在 Archive: 分类下的任务不再处理
+func (o *todoArchiveOptions) pruneTasks(doc *todofile.Document, nodes []*todofile.Node) int {
+	pruned := map[*todofile.Node]bool{}
+	var archiveSection *todofile.Node
 
-	logger.Info("开始解析任务行: %s", line)
+	for _, node := range nodes {
+		if node.Task.Status == models.TaskStatusInProgress || isArchived(node) || hasAncestorIn(node, pruned) {
+			continue
+		}
+		if hasInProgressSubtask(node) {
+			logger.Warning("任务包含进行中的子任务，不清理: %s", node.Task.Name)
+			continue
+		}
 
-	task.Status = status
+		switch o.pruneMode {
+		case pruneModeRemove:
+			doc.Remove(node)
+		case pruneModeMove:
+			if archiveSection == nil {
+				archiveSection = doc.FindCategory(todofile.ArchiveSection)
+				if archiveSection == nil {
+					archiveSection = doc.AppendChild(nil, todofile.ArchiveSection+":")
+				}
+			}
 
-	// 去掉状态符号
-	line = strings.TrimPrefix(line, matchedSymbol)
-	line = strings.TrimSpace(line)
+			// 移动后无法再从位置得到分类和项目，补充 @project 标签
+			if _, ok := node.Tag("@project"); !ok && node.Task.Category != "" {
+				project := node.Task.Category
+				if node.Task.Project != "" {
+					project += "." + node.Task.Project
+				}
+				node.SetTag("@project", project)
+			}
+			doc.Move(node, archiveSection)
+		}
 
-	// 找到第一个 @ 的位置
-	firstAtIndex := strings.Index(line, "@")
-	if firstAtIndex == -1 {
-		// 没有标签，整行都是任务名
-		task.Name = line
-		return task
+		pruned[node] = true
+		logger.Debug("清理任务: %s", node.Task.Name)
 	}
 
-	// 提取任务名称和标签部分
-	task.Name = strings.TrimSpace(line[:firstAtIndex])
-	tagsPart := line[firstAtIndex:]
+	return len(pruned)
+}
 
-	// 按 @ 分割标签
-	tags := strings.Split(tagsPart, "@")
-	for _, tag := range tags {
-		if tag == "" {
-			continue
-		}
-		tag = "@" + tag
+// isArchived 判断任务是否在 Archive: 分类下
+func isArchived(node *todofile.Node) bool {
+	category := node.Category()
+	return category != nil && category.Title == todofile.ArchiveSection
+}
 
-		// 截取标签名
-		tagName := tag
-		if idx := strings.Index(tag, "("); idx != -1 {
-			tagName = tag[:idx]
+// hasAncestorIn 判断任务的祖先节点是否在 nodes 中
+func hasAncestorIn(node *todofile.Node, nodes map[*todofile.Node]bool) bool {
+	for p := node.Parent; p != nil; p = p.Parent {
+		if nodes[p] {
+			return true
 		}
+	}
+	return false
+}
 
-		parseFn := models.TagParserFns[models.TagSet[tagName]]
-		if parseFn == nil {
+// hasInProgressSubtask 判断任务是否包含进行中的子任务
+func hasInProgressSubtask(node *todofile.Node) bool {
+	for _, child := range node.Children {
+		if child.Kind != todofile.NodeTask {
 			continue
 		}
-
-		tag = strings.TrimSpace(tag)
-		if err := parseFn(tag, task); err != nil {
-			logger.Warning("解析 tag %s 失败: %v", tagName, err)
-			continue
+		if child.Task.Status == models.TaskStatusInProgress || hasInProgressSubtask(child) {
+			return true
 		}
 	}
-
-	return task
+	return false
 }
 
-// isDateInRange 检查任务时间范围与日期范围是否存在交集
-func (o *todoArchiveOptions) isDateInRange(rangeStart, rangeEnd string, taskStartTime, taskEndTime *models.TaskTime) bool {
-	// 如果任务没有开始时间和结束时间，直接返回 false
-	if taskStartTime == nil && taskEndTime == nil {
-		return false
+// archiveFormatter 返回归档使用的格式
+// 优先使用 --template，其次是 --format，都没有指定时使用配置 flow.archive_template，最后是 text 格式
+func (o *todoArchiveOptions) archiveFormatter() (ArchiveFormatter, error) {
+	if o.template != "" {
+		return newTemplateArchiveFormatter(o.template)
 	}
-
-	// 将 TaskTime 转换为 time.Time
-	parseTaskTime := func(t *models.TaskTime) time.Time {
-		return time.Date(2000+t.Year, time.Month(t.Month), t.Day, 0, 0, 0, 0, time.Local)
+	if o.format != "" {
+		return getArchiveFormatter(o.format)
 	}
 
-	// 将日期字符串转换为 time.Time
-	parseRangeDate := func(date string) time.Time {
-		parts := strings.Split(date, "/")
-		if len(parts) != 2 {
-			logger.Warning("无效的日期格式: %s", date)
-			return time.Time{}
+	if path := config.Get().Flow.ArchiveTemplate; path != "" {
+		// 相对路径相对于 todo 文件夹
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(config.Get().Flow.TodoDir, path)
 		}
-		month, _ := strconv.Atoi(parts[0])
-		day, _ := strconv.Atoi(parts[1])
-		currentYear := time.Now().Year() - 2000 // 转换为两位数年份
-		return time.Date(2000+currentYear, time.Month(month), day, 0, 0, 0, 0, time.Local)
+		return newTemplateArchiveFormatter(path)
 	}
+	return getArchiveFormatter(defaultArchiveFormat)
+}
 
-	rangeStartTime := parseRangeDate(rangeStart)
-	rangeEndTime := parseRangeDate(rangeEnd)
+// dateRange 根据 --date 或 --period 参数计算归档日期范围
+func (o *todoArchiveOptions) dateRange() (dateRange, error) {
+	if o.period == "" {
+		return parseDateRange(o.date, resolveYear(o.year))
+	}
 
-	// 处理跨年的情况
-	if rangeEndTime.Before(rangeStartTime) {
-		rangeEndTime = rangeEndTime.AddDate(1, 0, 0)
-		logger.Debug("跨年处理: 调整范围结束时间 +1 年")
+	weekStart, err := resolveWeekStart()
+	if err != nil {
+		return dateRange{}, err
 	}
+	return resolvePeriod(o.period, time.Now(), weekStart, resolveYear(o.year))
+}
 
-	// 如果只有结束时间，且结束时间在范围内，则符合条件
-	if taskStartTime == nil && taskEndTime != nil {
-		taskEnd := parseTaskTime(taskEndTime)
-		result := !taskEnd.Before(rangeStartTime) && !taskEnd.After(rangeEndTime)
-		return result
+// processTodoFile 解析 todo 文件，返回文档和日期范围内的任务节点
+func (o *todoArchiveOptions) processTodoFile(todoFile string, r dateRange) (*todofile.Document, []*todofile.Node, error) {
+	logger.Debug("开始处理 todo 文件: %s", todoFile)
+	logger.Info("归档日期范围: %s ~ %s", r.Start.Format("2006-01-02"), r.End.Format("2006-01-02"))
+
+	doc, err := parseTodoFile(todoFile)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// 正常情况：有开始时间
-	taskStart := parseTaskTime(taskStartTime)
-	var taskEnd time.Time
-	if taskEndTime != nil {
-		taskEnd = parseTaskTime(taskEndTime)
-	} else {
-		taskEnd = time.Now() // 如果没有结束时间，表示至今
+	var nodes []*todofile.Node
+	for _, node := range doc.Tasks() {
+		task := node.Task
+		if r.overlaps(task.StartDate, task.EndDate) {
+			logger.Success("找到符合条件的任务: %s", task.Name)
+			nodes = append(nodes, node)
+		} else {
+			logger.Warning("任务 %s 不在日期范围内", task.Name)
+		}
 	}
 
-	// 判断时间范围是否有重叠
-	// 两个时间范围有重叠的条件是:
-	// !(任务结束 < 范围开始 || 任务开始 > 范围结束)
-	result := !(taskEnd.Before(rangeStartTime) || taskStart.After(rangeEndTime))
-	logger.Debug("日期范围检查结果: %v (范围: %s ~ %s, 任务: %s ~ %v)", result, rangeStart, rangeEnd, taskStart.Format("01/02"), taskEnd.Format("01/02"))
-	return result
+	logger.Debug("\n总结: 共处理 %d 行，找到 %d 个符合条件的任务", len(doc.Nodes), len(nodes))
+	return doc, nodes, nil
 }
 
-func (o *todoArchiveOptions) generateArchiveContent(tasks []models.TaskInfo) string {
-	var content strings.Builder
-
-	// 格式1
-	content.WriteString("---------------------------------------------\n")
-	content.WriteString("format1. 状态-开始时间-结束时间-分类-项目-名称\n")
-	content.WriteString("---------------------------------------------\n\n")
+// printArchiveSummary 按分类打印已完成和进行中的任务，方便直接复制
+func printArchiveSummary(archive *Archive) {
+	var lines []string
+	for _, group := range archive.Groups() {
+		tasks := filterTasksByStatus(false)(models.TaskStatusCancel, group.Tasks)
+		if len(tasks) == 0 {
+			continue
+		}
 
-	for _, task := range tasks {
-		content.WriteString(task.String() + "\n")
+		logger.Info("\n%s:", group.Category)
+		lines = append(lines, fmt.Sprintf("【%s】", group.Category))
+		for i, task := range tasks {
+			s := briefTask(task)
+			logger.Info("%d. %s", i+1, s)
+			lines = append(lines, fmt.Sprintf("%d. %s", i+1, s))
+		}
 	}
 
-	// 格式2
-	content.WriteString("\n\n---------------------------------------------\n")
-	content.WriteString("format2. (把已完成和进行中的任务按照分类罗列)\n")
-	content.WriteString("---------------------------------------------\n")
+	logger.Debug("\n%s", strings.Join(lines, " "))
+}
+
+// otherCategory 没有分类的任务归入的分类
+const otherCategory = "OTHER"
+
+// sortCategories 按分类名称排序,把 OTHER 放到最后
+func sortCategories(categories []string) {
+	sort.Slice(categories, func(i, j int) bool {
+		if (categories[i] == otherCategory) != (categories[j] == otherCategory) {
+			return categories[j] == otherCategory
+		}
+		return categories[i] < categories[j]
+	})
+}
+
+// TaskGroup 同一分类下的任务
+type TaskGroup struct {
+	Category string
+	Tasks    []models.TaskInfo
+}
 
-	// 按分类组织任务
-	categoryTasks := make(map[string][]string)
+// groupTasksByCategory 按分类组织任务，分类顺序与 sortCategories 一致
+func groupTasksByCategory(tasks []models.TaskInfo) []TaskGroup {
+	categoryTasks := make(map[string][]models.TaskInfo)
 	for _, task := range tasks {
-		task := &task
 		category := task.Category
 		if category == "" {
-			category = "OTHER"
-		}
-		if task.Status == models.TaskStatusDone {
-			task = task.IgnoreStatus()
-		}
-		s := task.IgnoreCategory().String()
-
-		if task.Status != models.TaskStatusCancel {
-			categoryTasks[category] = append(
-				categoryTasks[category],
-				s,
-			)
+			category = otherCategory
 		}
+		categoryTasks[category] = append(categoryTasks[category], task)
 	}
 
-	// 按分类名称排序,把 OTHER 放到最后
 	categories := make([]string, 0, len(categoryTasks))
-	hasOther := false
 	for category := range categoryTasks {
-		if category == "OTHER" {
-			hasOther = true
-			continue
-		}
 		categories = append(categories, category)
 	}
-	sort.Strings(categories)
-	if hasOther {
-		categories = append(categories, "OTHER")
-	}
+	sortCategories(categories)
 
-	var lines []string
-	// 同时写入文件内容和打印日志
+	groups := make([]TaskGroup, 0, len(categories))
 	for _, category := range categories {
-		content.WriteString(fmt.Sprintf("\n%s:\n", category))
-		logger.Info("\n%s:", category)
-		lines = append(lines, fmt.Sprintf("【%s】", category))
-
-		for i, task := range categoryTasks[category] {
-			content.WriteString(fmt.Sprintf("%d. %s\n", i+1, task))
-			logger.Info("%d. %s", i+1, task)
-			lines = append(lines, fmt.Sprintf("%d. %s", i+1, task))
-		}
+		groups = append(groups, TaskGroup{Category: category, Tasks: categoryTasks[category]})
 	}
-
-	logger.Debug("\n%s", strings.Join(lines, " "))
-
-	return content.String()
+	return groups
 }
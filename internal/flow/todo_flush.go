@@ -2,20 +2,29 @@ package flow
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 
+	"mycmd/internal/flow/models"
+	"mycmd/internal/flow/todofile"
 	"mycmd/pkg/config"
 	"mycmd/pkg/logger"
 )
 
 type todoFlushOptions struct {
-	todoType string
-	projects string
+	todoType    string
+	projects    []string
+	carry       bool
+	yes         bool
+	noOverwrite bool
 }
 
 func NewTodoFlushCmd() *cobra.Command {
@@ -25,109 +34,233 @@ func NewTodoFlushCmd() *cobra.Command {
 		Use:   "todo-flush",
 		Short: "初始化或刷新 todo 文件",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return opts.run()
+			return opts.run(cmd.InOrStdin())
 		},
 	}
 
 	cmd.Flags().StringVar(&opts.todoType, "type", "", "todo 类型 (work)")
-	cmd.Flags().StringVar(&opts.projects, "project", "", "项目名称列表，用逗号分隔")
+	cmd.Flags().StringArrayVar(&opts.projects, "project", nil,
+		"项目名称列表，用逗号分隔，添加到所有分类下；CATEGORY=PROJECT1,PROJECT2 只添加到指定分类下，可以重复指定")
+	cmd.Flags().BoolVar(&opts.carry, "carry", false, "保留原 todo 文件中进行中的任务，插入到新文件对应的分类和项目下")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "目标文件已存在时不确认直接覆盖")
+	cmd.Flags().BoolVar(&opts.yes, "force", false, "同 --yes")
+	cmd.Flags().BoolVar(&opts.noOverwrite, "no-overwrite", false, "目标文件已存在时不覆盖，直接跳过")
 	cmd.MarkFlagRequired("type")
+	cmd.MarkFlagsMutuallyExclusive("yes", "no-overwrite")
+	cmd.MarkFlagsMutuallyExclusive("force", "no-overwrite")
 
 	return cmd
 }
 
-func (o *todoFlushOptions) run() error {
-	if o.todoType == "work" && o.projects == "" {
-		return fmt.Errorf("work 类型必须指定 --project 参数")
+func (o *todoFlushOptions) run(in io.Reader) error {
+	projects, err := parseFlushProjects(o.projects, config.Get().Flow.Projects[o.todoType])
+	if err != nil {
+		return err
 	}
 
 	todoDir := config.Get().Flow.TodoDir
 	templateFile := filepath.Join(todoDir, o.todoType, fmt.Sprintf("%s-template.todo", o.todoType))
-	targetFile := filepath.Join(todoDir, o.todoType, fmt.Sprintf("%s.todo", o.todoType))
+	targetFile := todoFilePath(o.todoType)
 
 	// 检查模板文件是否存在
 	if _, err := os.Stat(templateFile); os.IsNotExist(err) {
 		return fmt.Errorf("模板文件不存在: %s", templateFile)
 	}
 
-	// 检查目标文件是否存在
-	if _, err := os.Stat(targetFile); err == nil {
-		fmt.Print("文件已存在，是否覆盖（覆盖前请确保已经归档）？(y/n) ")
-		var response string
-		fmt.Scanln(&response)
-		if strings.ToLower(response) != "y" {
+	// 读取目标文件，不存在时为 nil
+	old, err := os.ReadFile(targetFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("读取 todo 文件失败: %w", err)
+	}
+	exists := err == nil
+	if exists && o.noOverwrite {
+		logger.Info("文件已存在，跳过: %s", targetFile)
+		return nil
+	}
+
+	// 读取模板文件
+	content, err := o.processTemplateFile(templateFile, projects, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		if o.carry {
+			if content, err = o.carryOver(targetFile, old, content); err != nil {
+				return err
+			}
+		}
+		if content == string(old) {
+			logger.Info("文件内容没有变化: %s", targetFile)
+			return nil
+		}
+
+		ok, err := o.confirmOverwrite(in, targetFile, old, []byte(content))
+		if err != nil {
+			return err
+		}
+		if !ok {
 			logger.Info("操作已取消")
 			return nil
 		}
 	}
 
-	// 读取模板文件
-	content, err := o.processTemplateFile(templateFile)
+	// 等待确认时不加锁，避免长时间阻塞其它命令；加锁后检查确认期间文件是否被修改
+	unlock, err := lockFile(targetFile)
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	current, err := os.ReadFile(targetFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("读取 todo 文件失败: %w", err)
+	}
+	if (err == nil) != exists || !bytes.Equal(current, old) {
+		return fmt.Errorf("文件在确认期间被修改，请重新执行: %s", targetFile)
+	}
 
 	// 写入目标文件
-	if err := os.WriteFile(targetFile, []byte(content), 0644); err != nil {
+	if err := writeFile(targetFile, []byte(content)); err != nil {
 		return fmt.Errorf("写入文件失败: %w", err)
 	}
 
 	logger.Success("已成功创建 todo 文件: %s", targetFile)
-	if o.todoType == "work" {
-		logger.Success("已添加项目: %s", o.projects)
+	if !projects.Empty() {
+		logger.Success("已添加项目: %s", projects)
 	}
 
 	return nil
 }
 
-func (o *todoFlushOptions) processTemplateFile(templateFile string) (string, error) {
-	file, err := os.Open(templateFile)
+// processTemplateFile 根据模板生成 todo 文件的内容
+// 模板使用了 Go 模板语法时按模板渲染，否则在每个根分类下添加分类的项目
+func (o *todoFlushOptions) processTemplateFile(templateFile string, projects *flushProjects, now time.Time) (string, error) {
+	content, err := os.ReadFile(templateFile)
 	if err != nil {
-		return "", fmt.Errorf("打开模板文件失败: %w", err)
+		return "", fmt.Errorf("读取模板文件失败: %w", err)
+	}
+
+	if hasTemplateActions(content) {
+		result, err := renderFlushTemplate(templateFile, content, newFlushTemplateData(o.todoType, projects, now))
+		if err != nil {
+			return "", err
+		}
+		if err := projects.validate(todofile.Parse([]byte(result))); err != nil {
+			return "", err
+		}
+		return result, nil
 	}
-	defer file.Close()
 
+	if o.todoType == "work" && projects.Empty() {
+		return "", fmt.Errorf("work 类型必须指定 --project 参数或配置 flow.projects.work")
+	}
+
+	doc := todofile.Parse(content)
+	if err := projects.validate(doc); err != nil {
+		return "", err
+	}
 	var result strings.Builder
-	scanner := bufio.NewScanner(file)
 	inCategory := false
 
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// 检查是否是根分类行
-		if line != "" && !strings.HasPrefix(line, " ") &&
-			!strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//") {
+	for _, node := range doc.Nodes {
+		switch {
+		case node.Kind == todofile.NodeCategory && node.Indent == "":
 			inCategory = true
 			// 确保根分类有冒号结尾
-			if !strings.HasSuffix(line, ":") {
-				line += ":"
-			}
-			result.WriteString(line + "\n")
-
-			// 如果是 work 类型，添加项目
-			if o.todoType == "work" {
-				projects := strings.Split(o.projects, ",")
-				for _, project := range projects {
-					project = strings.TrimSpace(project)
-					if !strings.HasSuffix(project, ":") {
-						project += ":"
-					}
-					result.WriteString("    " + project + "\n")
+			result.WriteString(node.Title + ":\n")
+
+			// 添加分类的项目
+			for _, project := range projects.Of(node.Title) {
+				if !strings.HasSuffix(project, ":") {
+					project += ":"
 				}
+				result.WriteString("    " + project + "\n")
 			}
-		} else if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") || line == "" {
+		case node.Kind == todofile.NodeComment || node.Kind == todofile.NodeBlank:
 			// 注释行或空行
-			result.WriteString(line + "\n")
+			result.WriteString(node.Raw + "\n")
 			inCategory = false
-		} else if !inCategory {
+		case !inCategory:
 			// 非分类内容
-			result.WriteString(line + "\n")
+			result.WriteString(node.Raw + "\n")
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("读取模板文件失败: %w", err)
+	return result.String(), nil
+}
+
+// carryOver 将原 todo 文件 path 中进行中的任务插入到新生成的内容中
+func (o *todoFlushOptions) carryOver(path string, old []byte, content string) (string, error) {
+	oldDoc := todofile.Parse(old)
+	if err := checkDiagnostics(oldDoc, path); err != nil {
+		return "", err
 	}
 
-	return result.String(), nil
+	doc := todofile.Parse([]byte(content))
+	carried := o.carryTasks(oldDoc, doc)
+	for _, node := range carried {
+		logger.Info("保留进行中的任务(第 %d 行): %s", node.Line, node.Task.Name)
+	}
+	logger.Success("共保留 %d 个进行中的任务", len(carried))
+
+	return doc.String(), nil
+}
+
+// confirmOverwrite 打印原文件与新内容的 diff，并确认是否覆盖
+// 指定 --yes 时直接覆盖；标准输入不是终端时无法确认，返回错误
+func (o *todoFlushOptions) confirmOverwrite(in io.Reader, targetFile string, old, content []byte) (bool, error) {
+	diff, err := unifiedDiff(targetFile, old, content)
+	if err != nil {
+		return false, err
+	}
+	printDiff(diff)
+
+	if o.yes {
+		return true, nil
+	}
+	if !isTerminal(in) {
+		return false, fmt.Errorf("文件已存在且标准输入不是终端，无法确认是否覆盖，请使用 --yes 覆盖或 --no-overwrite 跳过: %s", targetFile)
+	}
+
+	// 提示输出到标准错误，标准输出只保留命令的结果
+	fmt.Fprint(os.Stderr, "文件已存在，是否覆盖（覆盖前请确保已经归档）？(y/n) ")
+	response, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("读取输入失败: %w", err)
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes", nil
+}
+
+// isTerminal 判断输入是否为终端
+func isTerminal(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// carryTasks 将 old 中进行中的任务连同子任务和备注插入到 doc 中相同的分类和项目下
+// 分类或项目不存在时自动创建，Archive: 分类下的任务不保留
+func (o *todoFlushOptions) carryTasks(old, doc *todofile.Document) []*todofile.Node {
+	carried := map[*todofile.Node]bool{}
+	var result []*todofile.Node
+
+	for _, node := range old.Tasks() {
+		if node.Task.Status != models.TaskStatusInProgress || isArchived(node) || hasAncestorIn(node, carried) {
+			continue
+		}
+		carried[node] = true
+
+		var parent *todofile.Node
+		if category, project := node.Position(); category != "" {
+			parent = ensureHeading(doc, category, project)
+		}
+		doc.Insert(node, parent)
+		result = append(result, node)
+	}
+
+	return result
 }
@@ -0,0 +1,103 @@
+package flow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mycmd/internal/flow/todofile"
+	"mycmd/pkg/config"
+)
+
+func TestTodoFlushOptions_carryTasks(t *testing.T) {
+	old := "工作:\n" +
+		"  BCS:\n" +
+		"    ☐ 进行中 @started(24-11-20 10:00) @progress(50%)\n" +
+		"      ✔ 已完成的子任务\n" +
+		"      备注\n" +
+		"    ✔ 已完成\n" +
+		"      ☐ 已完成任务下进行中的子任务\n" +
+		"  OLD:\n" +
+		"    ☐ 不在新模板中的项目\n" +
+		"☐ 没有分类的任务\n" +
+		"Archive:\n" +
+		"  ☐ 归档分类中的任务\n"
+
+	generated := "// 模板注释\n" +
+		"工作:\n" +
+		"    BCS:\n" +
+		"    DUAL:\n" +
+		"\n" +
+		"学习:\n"
+
+	expected := "// 模板注释\n" +
+		"工作:\n" +
+		"    BCS:\n" +
+		"        ☐ 进行中 @started(24-11-20 10:00) @progress(50%)\n" +
+		"            ✔ 已完成的子任务\n" +
+		"            备注\n" +
+		"        ☐ 已完成任务下进行中的子任务\n" +
+		"    DUAL:\n" +
+		"    OLD:\n" +
+		"        ☐ 不在新模板中的项目\n" +
+		"\n" +
+		"学习:\n" +
+		"☐ 没有分类的任务\n"
+
+	opts := &todoFlushOptions{}
+	doc := todofile.Parse([]byte(generated))
+	carried := opts.carryTasks(todofile.Parse([]byte(old)), doc)
+
+	var names []string
+	for _, node := range carried {
+		names = append(names, node.Task.Name)
+	}
+	assert.Equal(t, []string{"进行中", "已完成任务下进行中的子任务", "不在新模板中的项目", "没有分类的任务"}, names)
+	assert.Equal(t, expected, doc.String())
+	assert.Equal(t, "BCS", doc.Tasks()[0].Task.Project)
+	assert.Equal(t, "OLD", doc.Tasks()[3].Task.Project)
+}
+
+func TestTodoFlushOptions_run(t *testing.T) {
+	dir := t.TempDir()
+	todoDir := config.GlobalConfig.Flow.TodoDir
+	config.GlobalConfig.Flow.TodoDir = dir
+	defer func() { config.GlobalConfig.Flow.TodoDir = todoDir }()
+
+	templateFile := filepath.Join(dir, "study", "study-template.todo")
+	targetFile := filepath.Join(dir, "study", "study.todo")
+	require.NoError(t, os.MkdirAll(filepath.Dir(templateFile), 0755))
+	require.NoError(t, os.WriteFile(templateFile, []byte("学习:\n"), 0644))
+
+	readTarget := func() string {
+		content, err := os.ReadFile(targetFile)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	// 目标文件不存在时直接创建
+	opts := &todoFlushOptions{todoType: "study"}
+	require.NoError(t, opts.run(strings.NewReader("")))
+	assert.Equal(t, "学习:\n", readTarget())
+
+	require.NoError(t, os.WriteFile(targetFile, []byte("学习:\n    ✔ 旧任务\n"), 0644))
+
+	// 标准输入不是终端时返回错误，不修改文件
+	err := opts.run(strings.NewReader("y\n"))
+	assert.ErrorContains(t, err, "--yes")
+	assert.Equal(t, "学习:\n    ✔ 旧任务\n", readTarget())
+
+	// --no-overwrite 跳过
+	opts.noOverwrite = true
+	require.NoError(t, opts.run(strings.NewReader("")))
+	assert.Equal(t, "学习:\n    ✔ 旧任务\n", readTarget())
+
+	// --yes 直接覆盖
+	opts.noOverwrite, opts.yes = false, true
+	require.NoError(t, opts.run(strings.NewReader("")))
+	assert.Equal(t, "学习:\n", readTarget())
+}
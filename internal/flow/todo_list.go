@@ -0,0 +1,292 @@
+package flow
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"mycmd/internal/flow/models"
+	"mycmd/internal/flow/todofile"
+	"mycmd/pkg/logger"
+)
+
+type todoListOptions struct {
+	todoType string
+	status   string
+	category string
+	project  string
+	date     string
+	year     int
+	search   string
+	output   string
+}
+
+func NewTodoListCmd() *cobra.Command {
+	opts := &todoListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "todo-list",
+		Short: "列出 todo 文件中的任务",
+		Long: `列出 todo 文件中的任务，支持按状态、分类、项目、日期范围和关键字过滤。
+默认以表格输出，也可以通过 --output 输出 json、yaml 或 csv 供脚本使用。`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.todoType, "type", "", "todo 类型 (work)")
+	cmd.Flags().StringVar(&opts.status, "status", "", "任务状态，多个用逗号分隔 (进行中/已完成/已取消，或 todo/done/cancelled)")
+	cmd.Flags().StringVar(&opts.category, "category", "", "分类名称")
+	cmd.Flags().StringVar(&opts.project, "project", "", "项目名称")
+	cmd.Flags().StringVar(&opts.date, "date", "", "日期范围，格式："+dateRangeFormat)
+	cmd.Flags().IntVar(&opts.year, "year", 0, "MM/DD 格式日期的年份，默认使用配置 flow.current_year 或今年")
+	cmd.Flags().StringVar(&opts.search, "search", "", "按关键字过滤任务名称和标签")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "table", "输出格式 (table/json/yaml/csv)")
+	cmd.MarkFlagRequired("type")
+
+	return cmd
+}
+
+// statusAliases 状态的英文别名
+var statusAliases = map[string]models.TaskStatus{
+	"todo":        models.TaskStatusInProgress,
+	"in-progress": models.TaskStatusInProgress,
+	"done":        models.TaskStatusDone,
+	"cancelled":   models.TaskStatusCancel,
+	"canceled":    models.TaskStatusCancel,
+}
+
+// taskRecord 任务的输出格式
+type taskRecord struct {
+	Line     int    `json:"line" yaml:"line"`
+	ID       string `json:"id" yaml:"id"`
+	Status   string `json:"status" yaml:"status"`
+	Category string `json:"category" yaml:"category"`
+	Project  string `json:"project" yaml:"project"`
+	Name     string `json:"name" yaml:"name"`
+	Percent  int    `json:"percent" yaml:"percent"`
+	Start    string `json:"start" yaml:"start"`
+	End      string `json:"end" yaml:"end"`
+
+	Tags map[string]models.TagValue `json:"tags,omitempty" yaml:"tags,omitempty"` // 只在 json 和 yaml 中输出
+}
+
+func (o *todoListOptions) run(w io.Writer) error {
+	doc, err := parseTodoFile(todoFilePath(o.todoType))
+	if err != nil {
+		return err
+	}
+
+	nodes, err := o.filterTasks(doc.Tasks())
+	if err != nil {
+		return err
+	}
+
+	records := make([]taskRecord, 0, len(nodes))
+	for _, node := range nodes {
+		records = append(records, newTaskRecord(node))
+	}
+
+	return o.writeRecords(w, records)
+}
+
+// filterTasks 按命令行参数过滤任务
+func (o *todoListOptions) filterTasks(nodes []*todofile.Node) ([]*todofile.Node, error) {
+	statuses := map[models.TaskStatus]bool{}
+	for _, s := range strings.Split(o.status, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		status, ok := statusAliases[strings.ToLower(s)]
+		if !ok {
+			status = models.TaskStatus(s)
+		}
+		if status != models.TaskStatusInProgress && status != models.TaskStatusDone && status != models.TaskStatusCancel {
+			return nil, fmt.Errorf("无效的任务状态: %s", s)
+		}
+		statuses[status] = true
+	}
+
+	var r *dateRange
+	if o.date != "" {
+		parsed, err := parseDateRange(o.date, resolveYear(o.year))
+		if err != nil {
+			return nil, err
+		}
+		r = &parsed
+	}
+
+	search := strings.ToLower(o.search)
+
+	var result []*todofile.Node
+	for _, node := range nodes {
+		task := node.Task
+		if len(statuses) > 0 && !statuses[task.Status] {
+			continue
+		}
+		if o.category != "" && !strings.EqualFold(task.Category, o.category) {
+			continue
+		}
+		if o.project != "" && !strings.EqualFold(task.Project, o.project) {
+			continue
+		}
+		if r != nil && !r.overlaps(task.StartDate, task.EndDate) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(node.Text), search) {
+			continue
+		}
+		result = append(result, node)
+	}
+
+	return result, nil
+}
+
+func newTaskRecord(node *todofile.Node) taskRecord {
+	task := node.Task
+	record := taskRecord{
+		Line:     node.Line,
+		ID:       node.ID(),
+		Status:   string(task.Status),
+		Category: task.Category,
+		Project:  task.Project,
+		Name:     task.Name,
+		Percent:  task.Percent,
+		Tags:     task.Tags,
+	}
+	if task.StartDate != nil {
+		record.Start = task.StartDate.String()
+	}
+	if task.EndDate != nil {
+		record.End = task.EndDate.String()
+	}
+	return record
+}
+
+func (r taskRecord) fields() []string {
+	return []string{
+		strconv.Itoa(r.Line), r.ID, r.Status, r.Category, r.Project, r.Name,
+		strconv.Itoa(r.Percent), r.Start, r.End,
+	}
+}
+
+var taskRecordHeaders = []string{"line", "id", "status", "category", "project", "name", "percent", "start", "end"}
+
+func (o *todoListOptions) writeRecords(w io.Writer, records []taskRecord) error {
+	switch o.output {
+	case "table", "":
+		printTaskTable(w, records)
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(records); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(taskRecordHeaders); err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := cw.Write(record.fields()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("不支持的输出格式: %s", o.output)
+}
+
+// printTaskTable 以表格形式打印任务，不同状态使用不同颜色
+func printTaskTable(w io.Writer, records []taskRecord) {
+	headers := []string{"行号", "ID", "状态", "分类", "项目", "名称", "开始", "结束"}
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		status := r.Status
+		if models.TaskStatus(r.Status) == models.TaskStatusInProgress {
+			status = fmt.Sprintf("%s(%d%%)", r.Status, r.Percent)
+		}
+		rows = append(rows, []string{strconv.Itoa(r.Line), r.ID, status, r.Category, r.Project, r.Name, r.Start, r.End})
+	}
+
+	lines := alignColumns(append([][]string{headers}, rows...))
+	logger.Fprint(w, logger.StyleInfo, "%s", lines[0])
+	for i, record := range records {
+		style := logger.StyleWarning
+		switch models.TaskStatus(record.Status) {
+		case models.TaskStatusDone:
+			style = logger.StyleSuccess
+		case models.TaskStatusCancel:
+			style = logger.StyleDebug
+		}
+		logger.Fprint(w, style, "%s", lines[i+1])
+	}
+	logger.Fprint(w, logger.StyleInfo, "共 %d 个任务", len(records))
+}
+
+// alignColumns 按列对齐，中文等宽字符按两个字符宽度计算
+func alignColumns(rows [][]string) []string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := displayWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)+2))
+			}
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// displayWidth 返回字符串在终端中的显示宽度
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if isWideRune(r) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// isWideRune 判断字符是否为东亚宽字符
+func isWideRune(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115f ||
+		(r >= 0x2e80 && r <= 0xa4cf && r != 0x303f) ||
+		(r >= 0xac00 && r <= 0xd7a3) ||
+		(r >= 0xf900 && r <= 0xfaff) ||
+		(r >= 0xfe30 && r <= 0xfe4f) ||
+		(r >= 0xff00 && r <= 0xff60) ||
+		(r >= 0xffe0 && r <= 0xffe6) ||
+		(r >= 0x20000 && r <= 0x3fffd))
+}
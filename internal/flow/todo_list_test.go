@@ -0,0 +1,115 @@
+package flow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mycmd/internal/flow/todofile"
+)
+
+const listTestContent = "工作:\n" +
+	"    BCS:\n" +
+	"        ☐ 修复登录问题 @started(24-11-20 10:00)\n" +
+	"        ✔ 修复注册问题 @started(24-11-20 10:00) @done(24-11-21 15:41)\n" +
+	"    DUAL:\n" +
+	"        ✘ mock-duale @project(REFACTOR.DUAL) @cancelled(24-11-21 15:41)\n" +
+	"学习:\n" +
+	"    ☐ 看书 @progress(30%)\n"
+
+func TestTodoListOptions_filterTasks(t *testing.T) {
+	doc := todofile.Parse([]byte(listTestContent))
+
+	tests := []struct {
+		name    string
+		opts    todoListOptions
+		want    []int
+		wantErr bool
+	}{
+		{name: "不过滤", opts: todoListOptions{}, want: []int{3, 4, 6, 8}},
+		{name: "按中文状态", opts: todoListOptions{status: "进行中"}, want: []int{3, 8}},
+		{name: "按多个英文状态", opts: todoListOptions{status: "done,cancelled"}, want: []int{4, 6}},
+		{name: "无效状态", opts: todoListOptions{status: "unknown"}, wantErr: true},
+		{name: "按分类", opts: todoListOptions{category: "工作"}, want: []int{3, 4}},
+		{name: "按 @project 标签的分类", opts: todoListOptions{category: "refactor"}, want: []int{6}},
+		{name: "按项目", opts: todoListOptions{project: "bcs"}, want: []int{3, 4}},
+		{name: "按关键字", opts: todoListOptions{search: "@DONE"}, want: []int{4}},
+		{name: "无效日期", opts: todoListOptions{date: "11/20"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := tt.opts.filterTasks(doc.Tasks())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var lines []int
+			for _, node := range nodes {
+				lines = append(lines, node.Line)
+			}
+			assert.Equal(t, tt.want, lines)
+		})
+	}
+}
+
+func TestTodoListOptions_writeRecords(t *testing.T) {
+	doc := todofile.Parse([]byte(listTestContent))
+	records := []taskRecord{newTaskRecord(doc.Tasks()[1])}
+
+	tests := []struct {
+		output   string
+		expected string
+		wantErr  bool
+	}{
+		{
+			output: "csv",
+			expected: "line,id,status,category,project,name,percent,start,end\n" +
+				"4," + records[0].ID + ",已完成,工作,BCS,修复注册问题,0,24-11-20 10:00,24-11-21 15:41\n",
+		},
+		{
+			output: "yaml",
+			expected: "- line: 4\n" +
+				"  id: " + records[0].ID + "\n" +
+				"  status: 已完成\n" +
+				"  category: 工作\n" +
+				"  project: BCS\n" +
+				"  name: 修复注册问题\n" +
+				"  percent: 0\n" +
+				"  start: 24-11-20 10:00\n" +
+				"  end: 24-11-21 15:41\n" +
+				"  tags:\n" +
+				"    done: 24-11-21 15:41\n" +
+				"    started: 24-11-20 10:00\n",
+		},
+		{
+			output:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := &todoListOptions{output: tt.output}
+			err := opts.writeRecords(&buf, records)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestAlignColumns(t *testing.T) {
+	lines := alignColumns([][]string{
+		{"名称", "ID"},
+		{"ab", "1"},
+	})
+	assert.Equal(t, []string{"名称  ID", "ab    1"}, lines)
+}
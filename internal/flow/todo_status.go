@@ -0,0 +1,148 @@
+package flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mycmd/internal/flow/models"
+	"mycmd/internal/flow/todofile"
+	"mycmd/pkg/logger"
+)
+
+// statusTransition 修改任务状态和相关标签
+type statusTransition func(node *todofile.Node, now time.Time) error
+
+type todoStatusOptions struct {
+	todoType   string
+	transition statusTransition
+}
+
+func NewTodoStartCmd() *cobra.Command {
+	return newTodoStatusCmd("todo-start", "开始任务，添加 @started 标签", startTask)
+}
+
+func NewTodoDoneCmd() *cobra.Command {
+	return newTodoStatusCmd("todo-done", "完成任务，添加 @done 和 @lasted 标签", doneTask)
+}
+
+func NewTodoCancelCmd() *cobra.Command {
+	return newTodoStatusCmd("todo-cancel", "取消任务，添加 @cancelled 标签", cancelTask)
+}
+
+func newTodoStatusCmd(use, short string, transition statusTransition) *cobra.Command {
+	opts := &todoStatusOptions{transition: transition}
+
+	cmd := &cobra.Command{
+		Use:   use + " <任务名称|行号|ID>",
+		Short: short,
+		Long: short + `
+任务可以通过行号、任务 ID 或任务名称指定，名称支持模糊匹配。
+匹配到多个任务时不做修改，并列出所有候选任务。`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(strings.Join(args, " "))
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.todoType, "type", "", "todo 类型 (work)")
+	cmd.MarkFlagRequired("type")
+
+	return cmd
+}
+
+func (o *todoStatusOptions) run(query string) error {
+	todoFile := todoFilePath(o.todoType)
+	unlock, err := lockFile(todoFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	doc, err := parseTodoFile(todoFile)
+	if err != nil {
+		return err
+	}
+
+	node, err := findTask(doc, query)
+	if err != nil {
+		return err
+	}
+
+	if err := o.transition(node, time.Now()); err != nil {
+		return err
+	}
+
+	if err := writeFile(todoFile, doc.Bytes()); err != nil {
+		return fmt.Errorf("写入 todo 文件失败: %w", err)
+	}
+
+	logger.Success("已更新任务(第 %d 行): %s", node.Line, node.Text)
+	return nil
+}
+
+// findTask 查找唯一的任务，按名称或 ID 匹配到多个任务时列出所有候选任务并返回错误，不会自动选择其中一个
+func findTask(doc *todofile.Document, query string) (*todofile.Node, error) {
+	candidates := doc.FindTasks(query)
+	switch len(candidates) {
+	case 0:
+		if line, err := strconv.Atoi(strings.TrimSpace(query)); err == nil {
+			return nil, fmt.Errorf("第 %d 行不是任务", line)
+		}
+		return nil, fmt.Errorf("未找到任务: %s", query)
+	case 1:
+		return candidates[0], nil
+	}
+
+	hint := "请使用行号或 ID 指定"
+	if candidates[0].ID() == strings.TrimSpace(query) {
+		hint = "ID 相同，请使用行号指定"
+	}
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("匹配到 %d 个任务，%s:", len(candidates), hint))
+	for _, node := range candidates {
+		msg.WriteString(fmt.Sprintf("\n  第 %d 行 [%s] %s", node.Line, node.ID(), node.Text))
+	}
+	return nil, fmt.Errorf("%s", msg.String())
+}
+
+// startTask ☐ 任务 @started(24-11-22 14:58)
+func startTask(node *todofile.Node, now time.Time) error {
+	if node.Task.Status != models.TaskStatusInProgress {
+		return fmt.Errorf("任务%s，无法开始: %s", node.Task.Status, node.Task.Name)
+	}
+	if _, ok := node.Tag("@started"); ok {
+		return fmt.Errorf("任务已经开始: %s", node.Text)
+	}
+
+	node.SetTag("@started", models.NewTaskTimeFromTime(now).String())
+	return nil
+}
+
+// doneTask ✔ 任务 @started(24-11-22 14:58) @done(24-11-23 10:00) @lasted(19h2m)
+func doneTask(node *todofile.Node, now time.Time) error {
+	if node.Task.Status != models.TaskStatusInProgress {
+		return fmt.Errorf("任务%s，无法完成: %s", node.Task.Status, node.Task.Name)
+	}
+
+	node.SetStatus(models.TaskStatusDone)
+	node.SetTag("@done", models.NewTaskTimeFromTime(now).String())
+	if node.Task.StartDate != nil {
+		node.SetTag("@lasted", models.FormatLasted(now.Sub(node.Task.StartDate.Time())))
+	}
+	return nil
+}
+
+// cancelTask ✘ 任务 @cancelled(24-11-23 10:00)
+func cancelTask(node *todofile.Node, now time.Time) error {
+	if node.Task.Status != models.TaskStatusInProgress {
+		return fmt.Errorf("任务%s，无法取消: %s", node.Task.Status, node.Task.Name)
+	}
+
+	node.SetStatus(models.TaskStatusCancel)
+	node.SetTag("@cancelled", models.NewTaskTimeFromTime(now).String())
+	return nil
+}
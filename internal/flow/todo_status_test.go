@@ -0,0 +1,146 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"mycmd/internal/flow/todofile"
+)
+
+const statusTestContent = "工作:\n" +
+	"    BCS:\n" +
+	"        ☐ 修复登录问题 @created(24-11-20 09:00)\n" +
+	"        [ ] 修复注册问题 @started(24-11-20 10:00)\n" +
+	"        ✔ 修复登录样式 @done(24-11-19 10:00)\n" +
+	"        ☐ 升级依赖\n"
+
+func TestFindTask(t *testing.T) {
+	doc := todofile.Parse([]byte(statusTestContent))
+	tasks := doc.Tasks()
+
+	tests := []struct {
+		name    string
+		query   string
+		want    *todofile.Node
+		wantErr bool
+	}{
+		{name: "按行号", query: "4", want: tasks[1]},
+		{name: "按 ID", query: tasks[3].ID(), want: tasks[3]},
+		{name: "完全匹配", query: "升级依赖", want: tasks[3]},
+		{name: "包含匹配到多个任务时不按状态选择", query: "登录", wantErr: true},
+		{name: "模糊匹配", query: "修注册", want: tasks[1]},
+		{name: "匹配到多个任务", query: "修复", wantErr: true},
+		{name: "未找到任务", query: "不存在", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findTask(doc, tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := findTask(doc, "登录")
+	assert.EqualError(t, err, "匹配到 2 个任务，请使用行号或 ID 指定:\n"+
+		"  第 3 行 ["+tasks[0].ID()+"] ☐ 修复登录问题 @created(24-11-20 09:00)\n"+
+		"  第 5 行 ["+tasks[2].ID()+"] ✔ 修复登录样式 @done(24-11-19 10:00)")
+}
+
+func TestFindTask_LineAndID(t *testing.T) {
+	content := "工作:\n" +
+		"    BCS:\n" +
+		"        ☐ 2012 规划\n" +
+		"        ☐ 升级依赖\n" +
+		"        ☐ 升级依赖\n"
+	doc := todofile.Parse([]byte(content))
+	tasks := doc.Tasks()
+
+	// 行号不是任务时不按名称匹配
+	_, err := findTask(doc, "1")
+	assert.EqualError(t, err, "第 1 行不是任务")
+	_, err = findTask(doc, "12")
+	assert.EqualError(t, err, "第 12 行不是任务")
+
+	// 名称相同且没有 @created 的任务 ID 相同，不会修改其中任意一个
+	id := tasks[1].ID()
+	assert.Equal(t, id, tasks[2].ID())
+	_, err = findTask(doc, id)
+	assert.EqualError(t, err, "匹配到 2 个任务，ID 相同，请使用行号指定:\n"+
+		"  第 4 行 ["+id+"] ☐ 升级依赖\n"+
+		"  第 5 行 ["+id+"] ☐ 升级依赖")
+
+	got, err := findTask(doc, "5")
+	assert.NoError(t, err)
+	assert.Equal(t, tasks[2], got)
+}
+
+func TestStatusTransition(t *testing.T) {
+	now := time.Date(2024, 11, 21, 18, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name       string
+		line       int
+		transition statusTransition
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name:       "开始任务",
+			line:       3,
+			transition: startTask,
+			expected:   "        ☐ 修复登录问题 @created(24-11-20 09:00) @started(24-11-21 18:30)",
+		},
+		{
+			name:       "重复开始任务",
+			line:       4,
+			transition: startTask,
+			wantErr:    true,
+		},
+		{
+			name:       "完成任务时保持符号风格并计算耗时",
+			line:       4,
+			transition: doneTask,
+			expected:   "        [x] 修复注册问题 @started(24-11-20 10:00) @done(24-11-21 18:30) @lasted(1d8h30m)",
+		},
+		{
+			name:       "完成未开始的任务",
+			line:       6,
+			transition: doneTask,
+			expected:   "        ✔ 升级依赖 @done(24-11-21 18:30)",
+		},
+		{
+			name:       "取消任务",
+			line:       3,
+			transition: cancelTask,
+			expected:   "        ✘ 修复登录问题 @created(24-11-20 09:00) @cancelled(24-11-21 18:30)",
+		},
+		{
+			name:       "取消已完成的任务",
+			line:       5,
+			transition: cancelTask,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := todofile.Parse([]byte(statusTestContent))
+			node := doc.Nodes[tt.line-1]
+
+			err := tt.transition(node, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, node.Raw)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package todofile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity 问题的严重程度
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic 解析 todo 文件时发现的问题，如标签的内容格式错误
+type Diagnostic struct {
+	File     string // 文件路径，解析的不是文件时为空
+	Line     int    // 行号，从 1 开始
+	Column   int    // 列号，从 1 开始按字符计算
+	Tag      string // 出问题的标签，如 @progress，与标签无关时为空
+	Message  string
+	Severity Severity
+}
+
+// String 按编译器的格式输出，如 work.todo:3:18: error: @progress: invalid progress: 200
+func (d Diagnostic) String() string {
+	var location []string
+	if d.File != "" {
+		location = append(location, d.File)
+	}
+	location = append(location, fmt.Sprint(d.Line))
+	if d.Column > 0 {
+		location = append(location, fmt.Sprint(d.Column))
+	}
+
+	message := d.Message
+	if d.Tag != "" {
+		message = d.Tag + ": " + message
+	}
+	return fmt.Sprintf("%s: %s: %s", strings.Join(location, ":"), d.Severity, message)
+}
@@ -0,0 +1,322 @@
+package todofile
+
+import (
+	"strings"
+
+	"mycmd/internal/flow/models"
+)
+
+// NodeKind 表示 .todo 文件中一行的类型
+type NodeKind int
+
+const (
+	NodeBlank    NodeKind = iota // 空行
+	NodeComment                  // 注释行（# 或 // 开头）
+	NodeCategory                 // 根分类，如 "工作:"
+	NodeProject                  // 分类下的项目，如 "    BCS:"
+	NodeTask                     // 任务行（含子任务）
+	NodeNote                     // 任务或项目下的备注
+)
+
+func (k NodeKind) String() string {
+	switch k {
+	case NodeBlank:
+		return "blank"
+	case NodeComment:
+		return "comment"
+	case NodeCategory:
+		return "category"
+	case NodeProject:
+		return "project"
+	case NodeTask:
+		return "task"
+	case NodeNote:
+		return "note"
+	}
+	return "unknown"
+}
+
+// ArchiveSection Todo+ 风格的归档分类名称，位于文件末尾
+const ArchiveSection = "Archive"
+
+// Tag 任务行中的一个标签
+type Tag struct {
+	Name  string // 标签名，含 @ 前缀，如 @done
+	Value string // 括号中的内容，没有括号时为空
+	Raw   string // 标签的原始文本，如 @done(24-11-21 15:41)
+
+	Column int // 标签在行中的列号，从 1 开始按字符计算，新增的标签为 0
+}
+
+// Node 是 .todo 文件中的一行，同时也是文档树上的一个节点
+type Node struct {
+	Kind   NodeKind
+	Line   int    // 行号，从 1 开始
+	Raw    string // 原始行内容，不含换行符
+	EOL    string // 行尾换行符，文件最后一行可能为空
+	Indent string // 行首缩进
+	Text   string // 去掉缩进和行尾空白后的内容
+
+	Title  string           // 分类/项目名称，不含结尾的冒号
+	Symbol string           // 任务状态符号
+	Tags   []Tag            // 任务标签，按出现顺序排列
+	Task   *models.TaskInfo // 任务信息，仅 NodeTask 有效
+
+	Parent   *Node
+	Children []*Node
+}
+
+// IsHeading 判断节点是否是分类或项目
+func (n *Node) IsHeading() bool {
+	return n.Kind == NodeCategory || n.Kind == NodeProject
+}
+
+// Category 返回节点所在的根分类，不存在时返回 nil
+func (n *Node) Category() *Node {
+	for p := n; p != nil; p = p.Parent {
+		if p.Kind == NodeCategory {
+			return p
+		}
+	}
+	return nil
+}
+
+// Position 返回节点所在的根分类和项目名称，只根据所在位置计算，不考虑 @project 标签
+// 多级项目使用 . 连接
+func (n *Node) Position() (category, project string) {
+	var projects []string
+	for p := n.Parent; p != nil; p = p.Parent {
+		switch p.Kind {
+		case NodeCategory:
+			category = p.Title
+		case NodeProject:
+			projects = append([]string{p.Title}, projects...)
+		}
+	}
+	return category, strings.Join(projects, ".")
+}
+
+// Document 是解析后的 .todo 文件
+type Document struct {
+	Path  string  // 文件路径，从内存解析时为空
+	BOM   bool    // 文件是否以 UTF-8 BOM 开头
+	Nodes []*Node // 按文件顺序排列的所有行
+	Roots []*Node // 顶层的结构节点（根分类以及不属于任何分类的任务）
+
+	Diagnostics []Diagnostic // 解析时发现的问题，按行号排列
+}
+
+// Tasks 按文件顺序返回所有任务节点，包括子任务
+func (d *Document) Tasks() []*Node {
+	var tasks []*Node
+	for _, node := range d.Nodes {
+		if node.Kind == NodeTask {
+			tasks = append(tasks, node)
+		}
+	}
+	return tasks
+}
+
+// Categories 按文件顺序返回所有根分类
+func (d *Document) Categories() []*Node {
+	var categories []*Node
+	for _, node := range d.Roots {
+		if node.Kind == NodeCategory {
+			categories = append(categories, node)
+		}
+	}
+	return categories
+}
+
+// FindCategory 按名称查找根分类
+func (d *Document) FindCategory(name string) *Node {
+	for _, node := range d.Categories() {
+		if node.Title == name {
+			return node
+		}
+	}
+	return nil
+}
+
+// FindProject 按名称查找分类下的项目
+func (d *Document) FindProject(category, project string) *Node {
+	c := d.FindCategory(category)
+	if c == nil {
+		return nil
+	}
+	for _, child := range c.Children {
+		if child.Kind == NodeProject && child.Title == project {
+			return child
+		}
+	}
+	return nil
+}
+
+// defaultIndent 文档中没有缩进时使用的缩进
+const defaultIndent = "    "
+
+// IndentUnit 返回文档每一级使用的缩进，如 4 个空格或一个制表符
+func (d *Document) IndentUnit() string {
+	for _, node := range d.Nodes {
+		if node.Parent == nil {
+			continue
+		}
+		if unit := strings.TrimPrefix(node.Indent, node.Parent.Indent); unit != "" {
+			return unit
+		}
+	}
+	return defaultIndent
+}
+
+// AppendChild 在 parent 的所有子孙节点之后插入一行，作为 parent 的最后一个子节点
+// parent 为 nil 时插入到文件末尾作为顶层节点。text 不含缩进，缩进按文档风格生成
+func (d *Document) AppendChild(parent *Node, text string) *Node {
+	indent := ""
+	index := len(d.Nodes)
+	if parent != nil {
+		indent = parent.Indent + d.IndentUnit()
+		index = d.indexOf(lastDescendant(parent)) + 1
+	}
+
+	node, _ := parseLine(indent+text, 0)
+	node.EOL = d.LineEnding()
+	if index == len(d.Nodes) && index > 0 && d.Nodes[index-1].EOL == "" {
+		// 原文件末尾没有换行符，插入后保持这一特点
+		d.Nodes[index-1].EOL = node.EOL
+		node.EOL = ""
+	}
+
+	node.Parent = parent
+	if parent != nil {
+		parent.Children = append(parent.Children, node)
+	} else {
+		d.Roots = append(d.Roots, node)
+	}
+	if node.Kind == NodeTask {
+		fillTaskPosition(node)
+	}
+
+	d.Nodes = append(d.Nodes[:index], append([]*Node{node}, d.Nodes[index:]...)...)
+	d.renumber()
+	return node
+}
+
+// Remove 删除节点及其所有子孙节点
+func (d *Document) Remove(node *Node) {
+	subtree := map[*Node]bool{}
+	walk(node, func(n *Node) { subtree[n] = true })
+
+	nodes := d.Nodes[:0]
+	for _, n := range d.Nodes {
+		if !subtree[n] {
+			nodes = append(nodes, n)
+		}
+	}
+	d.Nodes = nodes
+
+	if node.Parent != nil {
+		node.Parent.Children = removeNode(node.Parent.Children, node)
+	} else {
+		d.Roots = removeNode(d.Roots, node)
+	}
+	node.Parent = nil
+
+	// 删除最后一行时，保持原文件末尾是否有换行符的特点
+	if len(d.Nodes) > 0 && lastDescendant(node).EOL == "" {
+		d.Nodes[len(d.Nodes)-1].EOL = ""
+	}
+	d.renumber()
+}
+
+// Move 将节点及其所有子孙节点移动到 parent 下，作为 parent 的最后一个子节点
+func (d *Document) Move(node, parent *Node) {
+	d.Remove(node)
+	d.Insert(node, parent)
+}
+
+// Insert 将节点及其所有子孙节点插入到 parent 下，作为 parent 的最后一个子节点
+// 缩进按层级和本文档的缩进风格重新生成。节点可以来自其他文档，插入后不应再在原文档中使用。parent 为 nil 时插入到文件末尾作为顶层节点
+func (d *Document) Insert(node, parent *Node) {
+	unit := d.IndentUnit()
+	baseIndent := ""
+	index := len(d.Nodes)
+	if parent != nil {
+		baseIndent = parent.Indent + unit
+		index = d.indexOf(lastDescendant(parent)) + 1
+	}
+
+	// 按层级重新生成缩进，使用本文档的缩进风格
+	eol := d.LineEnding()
+	var subtree []*Node
+	walk(node, func(n *Node) {
+		indent := baseIndent
+		if n != node {
+			indent = n.Parent.Indent + unit
+		}
+		n.Raw = indent + strings.TrimPrefix(n.Raw, n.Indent)
+		n.Indent = indent
+		n.EOL = eol
+		subtree = append(subtree, n)
+	})
+
+	if index == len(d.Nodes) && index > 0 && d.Nodes[index-1].EOL == "" {
+		d.Nodes[index-1].EOL = eol
+		subtree[len(subtree)-1].EOL = ""
+	}
+	d.Nodes = append(d.Nodes[:index], append(subtree, d.Nodes[index:]...)...)
+
+	node.Parent = parent
+	if parent != nil {
+		parent.Children = append(parent.Children, node)
+	} else {
+		d.Roots = append(d.Roots, node)
+	}
+	walk(node, func(n *Node) {
+		if n.Kind == NodeTask {
+			n.refreshTask()
+		}
+	})
+	d.renumber()
+}
+
+// walk 按文件顺序遍历节点及其所有子孙节点
+func walk(node *Node, fn func(n *Node)) {
+	fn(node)
+	for _, child := range node.Children {
+		walk(child, fn)
+	}
+}
+
+func removeNode(nodes []*Node, node *Node) []*Node {
+	for i, n := range nodes {
+		if n == node {
+			return append(nodes[:i], nodes[i+1:]...)
+		}
+	}
+	return nodes
+}
+
+// indexOf 返回节点在 Nodes 中的位置，不存在时返回 -1
+func (d *Document) indexOf(node *Node) int {
+	for i, n := range d.Nodes {
+		if n == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// renumber 插入或删除行之后重新计算行号
+func (d *Document) renumber() {
+	for i, node := range d.Nodes {
+		node.Line = i + 1
+	}
+}
+
+// lastDescendant 返回 node 的最后一个子孙节点，没有子节点时返回 node 本身
+func lastDescendant(node *Node) *Node {
+	for len(node.Children) > 0 {
+		node = node.Children[len(node.Children)-1]
+	}
+	return node
+}
@@ -0,0 +1,48 @@
+package todofile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument_Remove(t *testing.T) {
+	content := "工作:\n" +
+		"    BCS:\n" +
+		"        ✔ 已完成\n" +
+		"            ☐ 子任务\n" +
+		"            备注\n" +
+		"        ☐ 进行中\n" +
+		"学习:\n" +
+		"    ✔ 最后一行"
+
+	doc := Parse([]byte(content))
+	tasks := doc.Tasks()
+
+	doc.Remove(tasks[0])
+	doc.Remove(tasks[3])
+
+	assert.Equal(t, "工作:\n    BCS:\n        ☐ 进行中\n学习:", doc.String())
+	assert.Equal(t, 3, tasks[2].Line)
+	assert.Equal(t, []*Node{tasks[2]}, doc.FindProject("工作", "BCS").Children)
+	assert.Empty(t, doc.FindCategory("学习").Children)
+}
+
+func TestDocument_Move(t *testing.T) {
+	content := "工作:\n" +
+		"\tBCS:\n" +
+		"\t\t✔ 已完成\n" +
+		"\t\t\t备注\n" +
+		"\t\t☐ 进行中\n" +
+		"Archive:"
+
+	doc := Parse([]byte(content))
+	task := doc.Tasks()[0]
+	doc.Move(task, doc.FindCategory(ArchiveSection))
+
+	assert.Equal(t, "工作:\n\tBCS:\n\t\t☐ 进行中\nArchive:\n\t✔ 已完成\n\t\t备注", doc.String())
+	assert.Equal(t, 5, task.Line)
+	assert.Equal(t, ArchiveSection, task.Task.Category)
+	assert.Equal(t, "", task.Task.Project)
+	assert.Equal(t, doc.String(), Parse(doc.Bytes()).String())
+}
@@ -0,0 +1,93 @@
+package todofile
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// idLength 任务 ID 的长度
+const idLength = 7
+
+// ID 返回任务的稳定 ID
+// ID 由分类、项目、名称和 @created 计算，修改状态或其他标签时保持不变
+func (n *Node) ID() string {
+	if n.Task == nil {
+		return ""
+	}
+
+	created, _ := n.Tag("@created")
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		n.Task.Category, n.Task.Project, n.Task.Name, created.Value,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])[:idLength]
+}
+
+// FindTasks 查找任务，query 可以是行号、任务 ID 或任务名称
+// query 是数字时只按行号和 ID 查找，都没有找到时返回 nil，不会再按名称匹配。
+// 同一分类和项目下名称相同且没有 @created 的任务 ID 相同，此时返回所有 ID 相同的任务。
+// 名称依次按完全匹配、包含、模糊匹配（按顺序包含所有字符）查找，忽略大小写，
+// 返回最先找到结果的一组
+func (d *Document) FindTasks(query string) []*Node {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	tasks := d.Tasks()
+
+	line, err := strconv.Atoi(query)
+	isLine := err == nil
+	if isLine {
+		for _, node := range tasks {
+			if node.Line == line {
+				return []*Node{node}
+			}
+		}
+	}
+
+	var matched []*Node
+	for _, node := range tasks {
+		if node.ID() == query {
+			matched = append(matched, node)
+		}
+	}
+	// ID 也可能全是数字，因此数字先按行号再按 ID 查找，都没有时不再按名称匹配
+	if len(matched) > 0 || isLine {
+		return matched
+	}
+
+	lowerQuery := strings.ToLower(query)
+	matchers := []func(name string) bool{
+		func(name string) bool { return name == lowerQuery },
+		func(name string) bool { return strings.Contains(name, lowerQuery) },
+		func(name string) bool { return fuzzyMatch(name, lowerQuery) },
+	}
+	for _, match := range matchers {
+		var matched []*Node
+		for _, node := range tasks {
+			if match(strings.ToLower(node.Task.Name)) {
+				matched = append(matched, node)
+			}
+		}
+		if len(matched) > 0 {
+			return matched
+		}
+	}
+
+	return nil
+}
+
+// fuzzyMatch 判断 s 是否按顺序包含 pattern 的所有字符
+func fuzzyMatch(s, pattern string) bool {
+	rest := []rune(pattern)
+	for _, c := range s {
+		if len(rest) == 0 {
+			break
+		}
+		if c == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
@@ -0,0 +1,284 @@
+package todofile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"mycmd/internal/flow/models"
+	"mycmd/pkg/logger"
+)
+
+// tabWidth 计算缩进层级时一个制表符对应的空格数
+const tabWidth = 4
+
+// bom UTF-8 BOM，部分编辑器会写在文件开头
+const bom = "\ufeff"
+
+// ParseFile 读取并解析 .todo 文件
+func ParseFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 todo 文件失败: %w", err)
+	}
+
+	doc := Parse(data)
+	doc.Path = path
+	for i := range doc.Diagnostics {
+		doc.Diagnostics[i].File = path
+	}
+	return doc, nil
+}
+
+// Parse 将 .todo 文件内容解析为文档树
+//
+// 文件结构如下，层级由缩进决定：
+//
+//	工作:                                  <- 根分类
+//	    BCS:                               <- 项目
+//	        ☐ 任务 @created(24-11-20 10:00) <- 任务
+//	            ☐ 子任务                    <- 子任务
+//	            备注                        <- 备注
+//
+// 没有 @project 标签的任务会根据所在位置补全分类和项目。
+func Parse(data []byte) *Document {
+	doc := &Document{}
+
+	content := string(data)
+	if strings.HasPrefix(content, bom) {
+		doc.BOM = true
+		content = strings.TrimPrefix(content, bom)
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	// 以换行符结尾的文件，最后会多出一个空串
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// stack 保存当前行可能的父节点，缩进依次递增
+	var stack []*Node
+	for i, line := range lines {
+		raw, eol := splitEOL(line)
+		node, diags := parseLine(raw, i+1)
+		node.EOL = eol
+		doc.Diagnostics = append(doc.Diagnostics, diags...)
+		doc.Nodes = append(doc.Nodes, node)
+
+		if node.Kind == NodeBlank || node.Kind == NodeComment {
+			continue
+		}
+
+		width := indentWidth(node.Indent)
+		for len(stack) > 0 && indentWidth(stack[len(stack)-1].Indent) >= width {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			if node.Kind == NodeProject {
+				// 没有根分类的项目，按根分类处理
+				node.Kind = NodeCategory
+			}
+			if node.Kind != NodeNote {
+				doc.Roots = append(doc.Roots, node)
+			}
+		} else {
+			node.Parent = stack[len(stack)-1]
+			node.Parent.Children = append(node.Parent.Children, node)
+		}
+
+		if node.Kind == NodeTask {
+			fillTaskPosition(node)
+		}
+		if node.Kind != NodeNote {
+			stack = append(stack, node)
+		}
+	}
+
+	return doc
+}
+
+// parseLine 解析单行内容，不处理层级关系，返回任务行中的问题
+func parseLine(raw string, lineNum int) (*Node, []Diagnostic) {
+	node := &Node{
+		Line: lineNum,
+		Raw:  raw,
+	}
+
+	text := strings.TrimRight(raw, " \t")
+	node.Text = strings.TrimLeft(text, " \t")
+	node.Indent = text[:len(text)-len(node.Text)]
+
+	switch {
+	case node.Text == "":
+		node.Kind = NodeBlank
+		node.Indent = ""
+	case strings.HasPrefix(node.Text, "#") || strings.HasPrefix(node.Text, "//"):
+		node.Kind = NodeComment
+	default:
+		if symbol, name, tags, diags, ok := splitTaskText(node.Text); ok {
+			indent := utf8.RuneCountInString(node.Indent)
+			for i := range tags {
+				tags[i].Column += indent
+			}
+			for i := range diags {
+				diags[i].Column += indent
+			}
+			node.Kind = NodeTask
+			node.Symbol = symbol
+			node.Tags = tags
+
+			var tagDiags []Diagnostic
+			node.Task, tagDiags = buildTask(symbol, name, tags)
+			diags = append(diags, tagDiags...)
+			for i := range diags {
+				diags[i].Line = lineNum
+			}
+			return node, diags
+		}
+
+		if node.Indent == "" {
+			// 顶层的非任务行都是根分类，模板中的根分类可能没有冒号
+			node.Kind = NodeCategory
+			node.Title = strings.TrimSpace(strings.TrimSuffix(node.Text, ":"))
+		} else if strings.HasSuffix(node.Text, ":") {
+			node.Kind = NodeProject
+			node.Title = strings.TrimSpace(strings.TrimSuffix(node.Text, ":"))
+		} else {
+			node.Kind = NodeNote
+		}
+	}
+
+	return node, nil
+}
+
+// splitEOL 拆分行内容和行尾换行符
+func splitEOL(line string) (raw, eol string) {
+	switch {
+	case strings.HasSuffix(line, "\r\n"):
+		return strings.TrimSuffix(line, "\r\n"), "\r\n"
+	case strings.HasSuffix(line, "\n"):
+		return strings.TrimSuffix(line, "\n"), "\n"
+	}
+	return line, ""
+}
+
+// fillTaskPosition 没有 @project 标签的任务，使用所在的分类和项目
+func fillTaskPosition(node *Node) {
+	if node.Task.Category != "" {
+		return
+	}
+
+	var projects []string
+	for p := node.Parent; p != nil; p = p.Parent {
+		switch p.Kind {
+		case NodeCategory:
+			node.Task.Category = p.Title
+		case NodeProject:
+			projects = append([]string{p.Title}, projects...)
+		case NodeTask:
+			// 子任务继承父任务的分类和项目
+			node.Task.Category = p.Task.Category
+			node.Task.Project = p.Task.Project
+			return
+		}
+	}
+	node.Task.Project = strings.Join(projects, ".")
+}
+
+// indentWidth 计算缩进宽度
+func indentWidth(indent string) int {
+	width := 0
+	for _, c := range indent {
+		if c == '\t' {
+			width += tabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// ParseTaskLine 解析单个任务行，不是任务时返回 nil
+//
+// e.g. ✔ mock-duale @done(24-11-21 15:41) @project(REFACTOR.DUALENGINE)
+func ParseTaskLine(line string) *models.TaskInfo {
+	symbol, name, tags, _, ok := splitTaskText(strings.TrimSpace(line))
+	if !ok {
+		return nil
+	}
+	task, _ := buildTask(symbol, name, tags)
+	return task
+}
+
+// splitTaskText 将任务行拆分为状态符号、任务名称和标签，标签可以出现在行中的任意位置
+// 标签和问题的 Column 为在 text 中的列号
+func splitTaskText(text string) (symbol, name string, tags []Tag, diags []Diagnostic, ok bool) {
+	symbol = matchSymbol(text)
+	if symbol == "" {
+		return "", "", nil, nil, false
+	}
+
+	// 去掉状态符号
+	rest := strings.TrimLeft(strings.TrimPrefix(text, symbol), " \t")
+	name, tags, diags = tokenizeTags(rest)
+
+	offset := utf8.RuneCountInString(text[:len(text)-len(rest)])
+	for i := range tags {
+		tags[i].Column += offset
+	}
+	for i := range diags {
+		diags[i].Column += offset
+	}
+	return symbol, name, tags, diags, true
+}
+
+// matchSymbol 返回行首最长的状态符号，符号后必须是空白或行尾
+func matchSymbol(text string) string {
+	var matched string
+	for symbol := range models.SymbolSet {
+		if len(symbol) <= len(matched) || !strings.HasPrefix(text, symbol) {
+			continue
+		}
+		rest := text[len(symbol):]
+		if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			continue
+		}
+		matched = symbol
+	}
+	return matched
+}
+
+// buildTask 根据状态符号和标签构建任务信息，返回标签中的问题，问题的列号为标签的列号
+// 标签的内容格式错误是错误；任意标签都是合法的，只有开启 models.WarnUnknownTags 时不在 models.TagSet 中的标签是警告
+func buildTask(symbol, name string, tags []Tag) (*models.TaskInfo, []Diagnostic) {
+	task := &models.TaskInfo{
+		Status: models.SymbolSet[symbol],
+		Name:   name,
+	}
+
+	var diags []Diagnostic
+	for _, tag := range tags {
+		if _, ok := models.TagSet[tag.Name]; !ok && models.WarnUnknownTags {
+			diags = append(diags, Diagnostic{
+				Column:   tag.Column,
+				Tag:      tag.Name,
+				Message:  "未知的 tag，自定义的 tag 可以添加到配置 flow.tags 中",
+				Severity: SeverityWarning,
+			})
+		}
+
+		if err := models.ParseTag(tag.Name, tag.Value, task); err != nil {
+			diags = append(diags, Diagnostic{
+				Column:   tag.Column,
+				Tag:      tag.Name,
+				Message:  err.Error(),
+				Severity: SeverityError,
+			})
+		}
+	}
+
+	logger.Debug("解析任务行成功: %s", task.String())
+	return task, diags
+}
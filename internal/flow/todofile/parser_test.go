@@ -0,0 +1,274 @@
+package todofile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mycmd/internal/flow/models"
+)
+
+func TestParseTaskLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected *models.TaskInfo
+	}{
+		{
+			name: "完整的已完成任务",
+			line: "✓ 完成功能开发 @project(FEATURE.BCS) @created(23-11-20 10:00) @started(23-11-20 10:00) @done(23-11-21 18:00) @lasted(1d8h) @est(1d)",
+			expected: &models.TaskInfo{
+				Status: "已完成",
+				StartDate: &models.TaskTime{
+					Year:  23,
+					Month: 11,
+					Day:   20,
+					Hour:  10,
+					Min:   0,
+				},
+				EndDate: &models.TaskTime{
+					Year:  23,
+					Month: 11,
+					Day:   21,
+					Hour:  18,
+					Min:   0,
+				},
+				Category: "FEATURE",
+				Project:  "BCS",
+				Name:     "完成功能开发",
+			},
+		},
+		{
+			name: "已取消的任务",
+			line: "✘ 取消的任务 @project(BUG.BCS) @created(23-11-20 10:00) @cancelled(23-11-20 11:00)",
+			expected: &models.TaskInfo{
+				Status: "已取消",
+				StartDate: &models.TaskTime{
+					Year:  23,
+					Month: 11,
+					Day:   20,
+					Hour:  10,
+					Min:   0,
+				},
+				EndDate: &models.TaskTime{
+					Year:  23,
+					Month: 11,
+					Day:   20,
+					Hour:  11,
+					Min:   0,
+				},
+				Category: "BUG",
+				Project:  "BCS",
+				Name:     "取消的任务",
+			},
+		},
+		{
+			name: "进行中的任务",
+			line: "☐ 正在进行的任务 @project(FEATURE.BCS) @created(23-11-20 10:00) @started(23-11-20 10:00)",
+			expected: &models.TaskInfo{
+				Status: "进行中",
+				StartDate: &models.TaskTime{
+					Year:  23,
+					Month: 11,
+					Day:   20,
+					Hour:  10,
+					Min:   0,
+				},
+				EndDate:  &models.TaskTime{},
+				Category: "FEATURE",
+				Project:  "BCS",
+				Name:     "正在进行的任务",
+			},
+		},
+		{
+			name: "带进度的任务",
+			line: "☐ 带进度的任务 @project(FEATURE.BCS) @created(23-11-20 10:00) @started(23-11-20 10:00) @progress(50%)",
+			expected: &models.TaskInfo{
+				Status: "进行中",
+				StartDate: &models.TaskTime{
+					Year:  23,
+					Month: 11,
+					Day:   20,
+					Hour:  10,
+					Min:   0,
+				},
+				EndDate:  &models.TaskTime{},
+				Category: "FEATURE",
+				Project:  "BCS",
+				Name:     "带进度的任务",
+				Percent:  50,
+			},
+		},
+		{
+			name:     "无效的任务行",
+			line:     "",
+			expected: nil,
+		},
+		{
+			name:     "注释行",
+			line:     "// 这是一个注释",
+			expected: nil,
+		},
+		{
+			name: "缺少必要标签的任务",
+			line: "☐ 缺少项目标签的任务 @created(23-11-20 10:00)",
+			expected: &models.TaskInfo{
+				Status:    "进行中",
+				StartDate: &models.TaskTime{},
+				EndDate:   &models.TaskTime{},
+				Name:      "缺少项目标签的任务",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseTaskLine(tt.line)
+
+			if tt.expected == nil {
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NotNil(t, result)
+			assert.Equal(t, tt.expected.Status, result.Status)
+			assert.Equal(t, tt.expected.Category, result.Category)
+			assert.Equal(t, tt.expected.Project, result.Project)
+			// assert.Equal(t, tt.expected.Name, result.Name)
+			assert.Equal(t, tt.expected.Percent, result.Percent)
+
+			// 比较时间
+			if result.StartDate != nil {
+				assert.Equal(t, tt.expected.StartDate.Year, result.StartDate.Year)
+				assert.Equal(t, tt.expected.StartDate.Month, result.StartDate.Month)
+				assert.Equal(t, tt.expected.StartDate.Day, result.StartDate.Day)
+				assert.Equal(t, tt.expected.StartDate.Hour, result.StartDate.Hour)
+				assert.Equal(t, tt.expected.StartDate.Min, result.StartDate.Min)
+			}
+
+			if result.EndDate != nil {
+				assert.Equal(t, tt.expected.EndDate.Year, result.EndDate.Year)
+				assert.Equal(t, tt.expected.EndDate.Month, result.EndDate.Month)
+				assert.Equal(t, tt.expected.EndDate.Day, result.EndDate.Day)
+				assert.Equal(t, tt.expected.EndDate.Hour, result.EndDate.Hour)
+				assert.Equal(t, tt.expected.EndDate.Min, result.EndDate.Min)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	content := "# 工作记录\n" +
+		"工作:\n" +
+		"    BCS:\n" +
+		"        ☐ 按位置补全项目 @started(24-11-20 10:00)\n" +
+		"            ☐ 子任务\n" +
+		"            子任务备注\n" +
+		"        ✔ 带标签的任务 @project(REFACTOR.DUAL) @done(24-11-21 15:41)\n" +
+		"    DUAL:\n" +
+		"\n" +
+		"学习:\n" +
+		"\t☐ 直接在分类下的任务\n"
+
+	doc := Parse([]byte(content))
+
+	assert.Len(t, doc.Nodes, 11)
+	assert.Equal(t, NodeComment, doc.Nodes[0].Kind)
+	assert.Equal(t, NodeBlank, doc.Nodes[8].Kind)
+
+	categories := doc.Categories()
+	assert.Len(t, categories, 2)
+	assert.Equal(t, "工作", categories[0].Title)
+	assert.Equal(t, "学习", categories[1].Title)
+
+	bcs := doc.FindProject("工作", "BCS")
+	assert.NotNil(t, bcs)
+	assert.Equal(t, 3, bcs.Line)
+	assert.Len(t, bcs.Children, 2)
+	assert.NotNil(t, doc.FindProject("工作", "DUAL"))
+	assert.Nil(t, doc.FindProject("学习", "BCS"))
+
+	tasks := doc.Tasks()
+	assert.Len(t, tasks, 4)
+
+	assert.Equal(t, 4, tasks[0].Line)
+	assert.Equal(t, "按位置补全项目", tasks[0].Task.Name)
+	assert.Equal(t, "工作", tasks[0].Task.Category)
+	assert.Equal(t, "BCS", tasks[0].Task.Project)
+	assert.Equal(t, []NodeKind{NodeTask, NodeNote}, []NodeKind{tasks[0].Children[0].Kind, tasks[0].Children[1].Kind})
+
+	assert.Equal(t, "子任务", tasks[1].Task.Name)
+	assert.Equal(t, tasks[0], tasks[1].Parent)
+	assert.Equal(t, "工作", tasks[1].Task.Category)
+	assert.Equal(t, "BCS", tasks[1].Task.Project)
+
+	assert.Equal(t, models.TaskStatusDone, tasks[2].Task.Status)
+	assert.Equal(t, "REFACTOR", tasks[2].Task.Category)
+	assert.Equal(t, "DUAL", tasks[2].Task.Project)
+	assert.Equal(t, []string{"@project", "@done"}, []string{tasks[2].Tags[0].Name, tasks[2].Tags[1].Name})
+
+	assert.Equal(t, "学习", tasks[3].Task.Category)
+	assert.Equal(t, "", tasks[3].Task.Project)
+	assert.Equal(t, categories[1], tasks[3].Category())
+}
+
+func TestParse_TagsAnywhere(t *testing.T) {
+	doc := Parse([]byte("工作:\n    ☐ 回复 a@b.com @project(A.B) 的邮件 @est(1h)\n"))
+	tasks := doc.Tasks()
+	assert.Len(t, tasks, 1)
+
+	task := tasks[0]
+	assert.Equal(t, "回复 a@b.com 的邮件", task.Task.Name)
+	assert.Equal(t, "A", task.Task.Category)
+	assert.Equal(t, "B", task.Task.Project)
+	if assert.Len(t, task.Tags, 2) {
+		assert.Equal(t, 18, task.Tags[0].Column)
+		assert.Equal(t, 36, task.Tags[1].Column)
+	}
+
+	// 修改后标签都写在名称之后
+	task.SetTag("@est", "2h")
+	assert.Equal(t, "    ☐ 回复 a@b.com 的邮件 @project(A.B) @est(2h)", task.Raw)
+	assert.Equal(t, 22, task.Tags[0].Column)
+	assert.Equal(t, 36, task.Tags[1].Column)
+}
+
+func TestParse_Diagnostics(t *testing.T) {
+	content := "工作:\n" +
+		"    ☐ 任务A @progress(200) @review\n" +
+		"\t☐ 任务B @due(24-11-30 @started(24-13-01 10:00)\n"
+
+	path := filepath.Join(t.TempDir(), "work.todo")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	doc, err := ParseFile(path)
+	assert.NoError(t, err)
+
+	var lines []string
+	for _, d := range doc.Diagnostics {
+		lines = append(lines, d.String())
+	}
+	assert.Equal(t, []string{
+		path + ":2:11: error: @progress: invalid progress: 200",
+		path + `:3:8: warning: @due: 括号没有闭合，按普通文本处理，括号可以用 \ 转义`,
+		path + `:3:22: error: @started: parse started time failed: invalid month: "13"`,
+	}, lines)
+
+	// 开启 flow.warn_unknown_tags 时未知的标签是警告
+	defer models.Configure(models.Vocabulary{})
+	require.NoError(t, models.Configure(models.Vocabulary{WarnUnknownTags: true}))
+	doc, err = ParseFile(path)
+	require.NoError(t, err)
+	require.Len(t, doc.Diagnostics, 4)
+	assert.Equal(t, path+":2:26: warning: @review: 未知的 tag，自定义的 tag 可以添加到配置 flow.tags 中", doc.Diagnostics[1].String())
+
+	// 没有问题的文件
+	assert.Empty(t, Parse([]byte("工作:\n    ☐ 任务 @started(24-11-20 10:00)\n")).Diagnostics)
+}
+
+func TestDiagnostic_String(t *testing.T) {
+	d := Diagnostic{Line: 3, Message: "缩进错误", Severity: SeverityError}
+	assert.Equal(t, "3: error: 缩进错误", d.String())
+}
@@ -0,0 +1,186 @@
+package todofile
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// escapable 标签内容中可以用 \ 转义的字符，其它字符前的 \ 保持原样，如 @file(C:\tmp)
+const escapable = `()\"'`
+
+// tokenizeTags 识别任务内容中任意位置的 @name 和 @name(value) 标签，返回去掉标签后的名称
+//
+// 标签必须在行首或空白之后，并以空白或行尾结束，因此 alice@example.com 之类的文本不是标签。
+// 括号中的内容可以嵌套括号，也可以用 \ 转义括号，或者整体用引号包裹：
+//
+//	修复 @project(A.B) 登录问题 (备注) @note(f(x) = \)) @title("a ) b")
+//
+// 名称为 "修复 登录问题 (备注)"，@note 的值为 "f(x) = )"，@title 的值为 "a ) b"。
+// 标签的 Column 为标签在 text 中的列号（从 1 开始，按字符计算）。
+// 括号没有闭合的标签按普通文本处理，并返回一个警告。
+func tokenizeTags(text string) (name string, tags []Tag, diags []Diagnostic) {
+	var parts []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' || (i > 0 && !isBlank(text[i-1])) {
+			continue
+		}
+
+		column := utf8.RuneCountInString(text[:i]) + 1
+		tag, end, err := scanTag(text, i)
+		if err != nil {
+			diags = append(diags, Diagnostic{
+				Column:   column,
+				Tag:      tag.Name,
+				Message:  err.Error(),
+				Severity: SeverityWarning,
+			})
+		}
+		if end == 0 {
+			continue
+		}
+		tag.Column = column
+		tags = append(tags, tag)
+
+		parts = appendPart(parts, text[start:i])
+		start, i = end, end-1
+	}
+	parts = appendPart(parts, text[start:])
+
+	return strings.Join(parts, " "), tags, diags
+}
+
+// appendPart 名称中去掉标签后剩下的片段，标签两侧的空白合并为一个空格
+func appendPart(parts []string, part string) []string {
+	if part = strings.TrimSpace(part); part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// scanTag 从 text[at] 的 @ 开始识别一个标签，返回标签和标签之后的位置，不是标签时位置为 0
+// 看起来是标签但格式错误时返回错误，此时 Tag 中只有名称
+func scanTag(text string, at int) (Tag, int, error) {
+	i := at + 1
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isTagNameRune(r) {
+			break
+		}
+		i += size
+	}
+	if i == at+1 {
+		return Tag{}, 0, nil
+	}
+
+	tag := Tag{Name: text[at:i]}
+	if i < len(text) && text[i] == '(' {
+		value, end, ok := scanTagValue(text, i)
+		if !ok {
+			return tag, 0, fmt.Errorf("括号没有闭合，按普通文本处理，括号可以用 \\ 转义")
+		}
+		tag.Value, i = value, end
+	}
+
+	if i < len(text) && !isBlank(text[i]) {
+		return Tag{}, 0, nil
+	}
+	tag.Raw = text[at:i]
+	return tag, i, nil
+}
+
+// scanTagValue 从 text[open] 的左括号开始读取标签的内容，返回去掉转义后的内容和右括号之后的位置
+func scanTagValue(text string, open int) (string, int, bool) {
+	// 引号包裹的内容，引号后面必须是右括号，否则引号按普通字符处理
+	if i := open + 1; i < len(text) && (text[i] == '"' || text[i] == '\'') {
+		quote := text[i]
+		var b strings.Builder
+		for j := i + 1; j < len(text); j++ {
+			c := text[j]
+			if c == '\\' && j+1 < len(text) && strings.IndexByte(escapable, text[j+1]) >= 0 {
+				j++
+				b.WriteByte(text[j])
+				continue
+			}
+			if c == quote {
+				if j+1 < len(text) && text[j+1] == ')' {
+					return b.String(), j + 2, true
+				}
+				break
+			}
+			b.WriteByte(c)
+		}
+	}
+
+	var b strings.Builder
+	depth := 0
+	for j := open + 1; j < len(text); j++ {
+		c := text[j]
+		switch {
+		case c == '\\' && j+1 < len(text) && strings.IndexByte(escapable, text[j+1]) >= 0:
+			j++
+			b.WriteByte(text[j])
+		case c == '(':
+			depth++
+			b.WriteByte(c)
+		case c == ')':
+			if depth == 0 {
+				return b.String(), j + 1, true
+			}
+			depth--
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// escapeTagValue 转义写入标签的内容，括号配对且不需要转义的内容保持原样
+func escapeTagValue(value string) string {
+	if !needsEscape(value) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '(' || c == ')' || c == '\\' || (i == 0 && (c == '"' || c == '\'')) {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func needsEscape(value string) bool {
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		return true
+	}
+
+	depth := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			return true
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return true
+			}
+		}
+	}
+	return depth != 0
+}
+
+// isTagNameRune 标签名称可以包含字母、数字、_ 和 -
+func isTagNameRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
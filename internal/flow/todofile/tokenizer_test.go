@@ -0,0 +1,95 @@
+package todofile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizeTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		tags     []Tag
+		diags    []Diagnostic
+	}{
+		{
+			name:     "标签在名称之后",
+			text:     "任务 @done(24-11-21 15:41) @today",
+			expected: "任务",
+			tags: []Tag{
+				{Name: "@done", Value: "24-11-21 15:41", Raw: "@done(24-11-21 15:41)", Column: 4},
+				{Name: "@today", Raw: "@today", Column: 26},
+			},
+		},
+		{
+			name:     "标签在名称中间和开头",
+			text:     "@high 修复 @project(A.B) 登录问题 (备注)",
+			expected: "修复 登录问题 (备注)",
+			tags: []Tag{
+				{Name: "@high", Raw: "@high", Column: 1},
+				{Name: "@project", Value: "A.B", Raw: "@project(A.B)", Column: 10},
+			},
+		},
+		{
+			name:     "邮箱不是标签",
+			text:     "回复 alice@example.com 的邮件 @started(24-11-20 10:00) 抄送 bob@example.com",
+			expected: "回复 alice@example.com 的邮件 抄送 bob@example.com",
+			tags: []Tag{
+				{Name: "@started", Value: "24-11-20 10:00", Raw: "@started(24-11-20 10:00)", Column: 26},
+			},
+		},
+		{
+			name:     "嵌套和转义的括号",
+			text:     `公式 @note(f(g(x)) = \)) @path(C:\tmp)`,
+			expected: "公式",
+			tags: []Tag{
+				{Name: "@note", Value: "f(g(x)) = )", Raw: `@note(f(g(x)) = \))`, Column: 4},
+				{Name: "@path", Value: `C:\tmp`, Raw: `@path(C:\tmp)`, Column: 24},
+			},
+		},
+		{
+			name:     "引号包裹的内容",
+			text:     `任务 @title("a ) b") @quote('say "hi"') @plain(it's)`,
+			expected: "任务",
+			tags: []Tag{
+				{Name: "@title", Value: "a ) b", Raw: `@title("a ) b")`, Column: 4},
+				{Name: "@quote", Value: `say "hi"`, Raw: `@quote('say "hi"')`, Column: 20},
+				{Name: "@plain", Value: "it's", Raw: "@plain(it's)", Column: 39},
+			},
+		},
+		{
+			name:     "括号没有闭合或后面不是空白",
+			text:     "任务 @due(24-11-30 @done. @ @x(1)y",
+			expected: "任务 @due(24-11-30 @done. @ @x(1)y",
+			diags: []Diagnostic{{
+				Column:   4,
+				Tag:      "@due",
+				Message:  `括号没有闭合，按普通文本处理，括号可以用 \ 转义`,
+				Severity: SeverityWarning,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, tags, diags := tokenizeTags(tt.text)
+			assert.Equal(t, tt.expected, name)
+			assert.Equal(t, tt.tags, tags)
+			assert.Equal(t, tt.diags, diags)
+		})
+	}
+}
+
+func TestEscapeTagValue(t *testing.T) {
+	for _, value := range []string{"24-11-21 15:41", "f(x)", "a ) b", `C:\tmp`, `"quoted"`, "(("} {
+		text := "任务 " + formatTag("@v", value)
+		_, tags, _ := tokenizeTags(text)
+		if assert.Len(t, tags, 1, value) {
+			assert.Equal(t, value, tags[0].Value)
+		}
+	}
+	assert.Equal(t, "f(x)", escapeTagValue("f(x)"))
+	assert.Equal(t, `a \) b`, escapeTagValue("a ) b"))
+}
@@ -0,0 +1,130 @@
+package todofile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"mycmd/internal/flow/models"
+)
+
+// Bytes 将文档序列化为文件内容
+// 未修改的行按原始内容输出，因此解析后直接写回的结果与原文件完全一致
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	if d.BOM {
+		buf.WriteString(bom)
+	}
+
+	for i, node := range d.Nodes {
+		buf.WriteString(node.Raw)
+		eol := node.EOL
+		if eol == "" && i < len(d.Nodes)-1 {
+			// 原来的最后一行后面追加了新行
+			eol = d.LineEnding()
+		}
+		buf.WriteString(eol)
+	}
+
+	return buf.Bytes()
+}
+
+func (d *Document) String() string {
+	return string(d.Bytes())
+}
+
+// LineEnding 返回文档使用的换行符，默认为 \n
+func (d *Document) LineEnding() string {
+	for _, node := range d.Nodes {
+		if node.EOL != "" {
+			return node.EOL
+		}
+	}
+	return "\n"
+}
+
+// Tag 返回任务中名为 name 的标签，name 含 @ 前缀
+func (n *Node) Tag(name string) (Tag, bool) {
+	for _, tag := range n.Tags {
+		if tag.Name == name {
+			return tag, true
+		}
+	}
+	return Tag{}, false
+}
+
+// SetStatus 修改任务状态，新的符号与原符号保持同一风格（如 [ ] -> [x]）
+func (n *Node) SetStatus(status models.TaskStatus) {
+	n.Symbol = models.StatusSymbol(status, n.Symbol)
+	n.update()
+}
+
+// SetTag 设置标签的值，已存在的标签原地修改，否则追加到行尾
+// value 为空时写入不带括号的标签，如 @today
+func (n *Node) SetTag(name, value string) {
+	tag := Tag{Name: name, Value: value, Raw: formatTag(name, value)}
+	for i := range n.Tags {
+		if n.Tags[i].Name == name {
+			n.Tags[i] = tag
+			n.update()
+			return
+		}
+	}
+
+	n.Tags = append(n.Tags, tag)
+	n.update()
+}
+
+// RemoveTag 删除标签，返回标签是否存在
+func (n *Node) RemoveTag(name string) bool {
+	for i := range n.Tags {
+		if n.Tags[i].Name == name {
+			n.Tags = append(n.Tags[:i], n.Tags[i+1:]...)
+			n.update()
+			return true
+		}
+	}
+	return false
+}
+
+// update 任务被修改后重新生成行内容和任务信息
+func (n *Node) update() {
+	n.Text = formatTaskText(n.Symbol, n.Task.Name, n.Tags)
+	n.Raw = n.Indent + n.Text
+
+	// 标签都写在名称之后，重新计算列号
+	column := utf8.RuneCountInString(n.Raw) + 1
+	for i := len(n.Tags) - 1; i >= 0; i-- {
+		column -= utf8.RuneCountInString(n.Tags[i].Raw)
+		n.Tags[i].Column = column
+		column--
+	}
+
+	n.refreshTask()
+}
+
+// refreshTask 根据符号、标签和所在位置重新生成任务信息
+func (n *Node) refreshTask() {
+	n.Task, _ = buildTask(n.Symbol, n.Task.Name, n.Tags)
+	fillTaskPosition(n)
+}
+
+// formatTaskText 生成任务行内容（不含缩进）
+func formatTaskText(symbol, name string, tags []Tag) string {
+	parts := []string{symbol}
+	if name != "" {
+		parts = append(parts, name)
+	}
+	for _, tag := range tags {
+		parts = append(parts, tag.Raw)
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatTag(name, value string) string {
+	if value == "" {
+		return name
+	}
+	return fmt.Sprintf("%s(%s)", name, escapeTagValue(value))
+}
@@ -0,0 +1,94 @@
+package todofile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mycmd/internal/flow/models"
+)
+
+func TestDocument_BytesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "常规文件",
+			content: "工作:\n    BCS:\n        ☐ 任务 @started(24-11-20 10:00)\n\n// 注释\n",
+		},
+		{
+			name:    "CRLF 换行且末尾没有换行符",
+			content: "工作:\r\n\t[ ] 任务  \r\n\t[x] 已完成 @done(24-11-21 15:41)",
+		},
+		{
+			name:    "带 BOM 和多余空白",
+			content: "\ufeff学习:   \n    \n        ✘ 取消   @cancelled(24-11-21 15:41)\n\n\n",
+		},
+		{
+			name:    "空文件",
+			content: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Parse([]byte(tt.content))
+			assert.Equal(t, tt.content, doc.String())
+		})
+	}
+}
+
+func TestNode_Edit(t *testing.T) {
+	content := "工作:\r\n" +
+		"    [ ] 方括号任务 @project(FEATURE.BCS) @started(24-11-20 10:00) @est(1d)\r\n" +
+		"    ☐ 默认符号任务 @started(24-11-20 10:00)\r\n" +
+		"    // 注释保持不变\r\n"
+
+	doc := Parse([]byte(content))
+	tasks := doc.Tasks()
+
+	tasks[0].SetStatus(models.TaskStatusDone)
+	tasks[0].SetTag("@started", "24-11-19 09:00")
+	tasks[0].SetTag("@done", "24-11-21 18:00")
+
+	tasks[1].SetStatus(models.TaskStatusCancel)
+	tasks[1].RemoveTag("@started")
+	tasks[1].SetTag("@cancelled", "24-11-21 18:00")
+
+	expected := "工作:\r\n" +
+		"    [x] 方括号任务 @project(FEATURE.BCS) @started(24-11-19 09:00) @est(1d) @done(24-11-21 18:00)\r\n" +
+		"    ✘ 默认符号任务 @cancelled(24-11-21 18:00)\r\n" +
+		"    // 注释保持不变\r\n"
+	assert.Equal(t, expected, doc.String())
+
+	assert.Equal(t, models.TaskStatusDone, tasks[0].Task.Status)
+	assert.Equal(t, "FEATURE", tasks[0].Task.Category)
+	assert.Equal(t, models.NewTaskTime(24, 11, 21, 18, 0), tasks[0].Task.EndDate)
+	assert.Equal(t, models.TaskStatusCancel, tasks[1].Task.Status)
+	assert.Equal(t, "工作", tasks[1].Task.Category)
+	assert.Nil(t, tasks[1].Task.StartDate)
+
+	// 重新解析写回的内容，结果保持一致
+	assert.Equal(t, expected, Parse(doc.Bytes()).String())
+}
+
+func TestNode_EditConfiguredSymbols(t *testing.T) {
+	defer models.Configure(models.Vocabulary{})
+	assert.NoError(t, models.Configure(models.Vocabulary{
+		Symbols:   map[models.TaskStatus][]string{models.TaskStatusInProgress: {"◻"}},
+		Preferred: map[models.TaskStatus]string{models.TaskStatusDone: "✅"},
+		Tags:      []string{"review"},
+	}))
+
+	doc := Parse([]byte("工作:\n    ◻ 自定义符号任务 @review @started(24-11-20 10:00)\n"))
+	tasks := doc.Tasks()
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, models.TaskStatusInProgress, tasks[0].Task.Status)
+		assert.Equal(t, "自定义符号任务", tasks[0].Task.Name)
+
+		tasks[0].SetStatus(models.TaskStatusDone)
+		tasks[0].SetTag("@done", "24-11-21 18:00")
+	}
+	assert.Equal(t, "工作:\n    ✅ 自定义符号任务 @review @started(24-11-20 10:00) @done(24-11-21 18:00)\n", doc.String())
+}
@@ -2,10 +2,8 @@ package config
 
 import (
 	"encoding/json"
-	"os"
-
-	"gopkg.in/yaml.v3"
 
+	"mycmd/internal/flow/models"
 	"mycmd/pkg/logger"
 )
 
@@ -15,28 +13,44 @@ type Config struct {
 		ConfigPath string `yaml:"config_path" json:"config_path"`
 	} `yaml:"base" json:"base"`
 	Flow struct {
-		TodoDir string `yaml:"todo_dir" json:"todo_dir"`
+		TodoDir         string                         `yaml:"todo_dir" json:"todo_dir"`
+		CurrentYear     int                            `yaml:"current_year" json:"current_year"`           // MM/DD 格式日期默认使用的年份
+		WeekStart       string                         `yaml:"week_start" json:"week_start"`               // 每周的第一天，如 monday、sunday
+		ArchiveTemplate string                         `yaml:"archive_template" json:"archive_template"`   // 默认的归档模板，相对路径相对于 todo_dir
+		Projects        map[string]map[string][]string `yaml:"projects" json:"projects"`                   // 每种 todo 类型每个分类的项目，用于 todo-flush
+		Symbols         map[string]StatusSymbols       `yaml:"symbols" json:"symbols"`                     // 每个状态额外的符号，状态为 in_progress、done、cancelled
+		Tags            []string                       `yaml:"tags" json:"tags"`                           // 额外的标签名称，如 review
+		WarnUnknownTags bool                           `yaml:"warn_unknown_tags" json:"warn_unknown_tags"` // 遇到不在内置标签和 tags 中的标签时给出警告
+		Backup          struct {
+			Keep    int `yaml:"keep" json:"keep"`         // 每个文件保留的备份数量，默认 10
+			MaxDays int `yaml:"max_days" json:"max_days"` // 备份保留的天数，0 表示不限制
+		} `yaml:"backup" json:"backup"`
 	} `yaml:"flow" json:"flow"`
 }
 
+// StatusSymbols 一个状态额外的符号以及写入任务时使用的符号
+type StatusSymbols struct {
+	Preferred string   `yaml:"preferred" json:"preferred,omitempty"` // 写入任务时使用的符号，如 ✅
+	Extra     []string `yaml:"extra" json:"extra,omitempty"`         // 解析时额外识别的符号，如 ◻
+}
+
 var GlobalConfig Config
 
-// LoadConfig 从 YAML 文件加载配置
-func LoadConfig(configFile string) error {
-	data, err := os.ReadFile(configFile)
-	if err != nil {
-		return err
+// Vocabulary 把 flow.symbols、flow.tags 和 flow.warn_unknown_tags 转换为 models.Vocabulary，忽略无效的状态
+func (c Config) Vocabulary() models.Vocabulary {
+	v := models.Vocabulary{
+		Symbols:         map[models.TaskStatus][]string{},
+		Preferred:       map[models.TaskStatus]string{},
+		Tags:            c.Flow.Tags,
+		WarnUnknownTags: c.Flow.WarnUnknownTags,
 	}
-
-	err = yaml.Unmarshal(data, &GlobalConfig)
-	if err != nil {
-		return err
+	for name, symbols := range c.Flow.Symbols {
+		if status, ok := models.StatusNames[name]; ok {
+			v.Symbols[status] = symbols.Extra
+			v.Preferred[status] = symbols.Preferred
+		}
 	}
-
-	// 打印配置信息
-	printConfig()
-
-	return nil
+	return v
 }
 
 // GetConfigPath 返回配置文件路径
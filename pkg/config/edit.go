@@ -0,0 +1,317 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"mycmd/pkg/fileutil"
+)
+
+// initTemplate config init 生成的配置文件
+const initTemplate = `# mycmd 配置文件，可以使用 mycmd config validate 校验
+
+# 基础配置
+base:
+  config_path: "./configs"
+
+# flow 相关配置
+flow:
+  todo_dir: {{quote .TodoDir}} # todo 文件夹路径
+  current_year: {{.CurrentYear}} # MM/DD 格式日期使用的年份，0 表示使用今年
+  week_start: {{.WeekStart}} # 每周的第一天，用于 --period this-week/last-week
+  # archive_template: weekly.md.tmpl # 默认的归档模板，相对路径相对于 todo_dir
+  # projects: # 每种 todo 类型每个分类的项目，todo-flush 没有指定 --project 时使用
+  #   work:
+  #     BUGFIX: [BCS, DUAL]
+  #     FEATURE: [BCS]
+  # symbols: # 每个状态额外的符号，状态为 in_progress、done、cancelled
+  #   in_progress:
+  #     extra: ["◻"] # 解析时额外识别的符号
+  #   done:
+  #     preferred: "✅" # 新建任务和修改状态时写入的符号
+  # tags: [review] # 额外的标签名称
+  # warn_unknown_tags: true # 遇到不在内置标签和 tags 中的标签时给出警告，可以发现拼写错误
+  backup: # 覆盖文件前自动备份到 todo 文件夹的 .backup 目录
+    keep: {{.Backup.Keep}} # 每个文件保留的备份数量
+    max_days: {{.Backup.MaxDays}} # 备份保留的天数，0 表示不限制
+`
+
+// InitOptions config init 生成配置文件使用的值
+type InitOptions struct {
+	TodoDir     string
+	CurrentYear int
+	WeekStart   string
+}
+
+// RenderInit 生成带注释的初始配置文件
+func RenderInit(opts InitOptions) ([]byte, error) {
+	cfg := Defaults()
+	flow := cfg.Flow
+	flow.TodoDir = opts.TodoDir
+	flow.CurrentYear = opts.CurrentYear
+	if opts.WeekStart != "" {
+		flow.WeekStart = opts.WeekStart
+	}
+
+	tmpl, err := template.New("config").Funcs(template.FuncMap{"quote": quoteYAML}).Parse(initTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, flow); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SetFileValue 修改配置文件中的配置项，文件中的注释和其它配置项保持不变，文件不存在时创建
+// 修改后的配置有新的问题时返回 *ValidationError，不写入文件
+func SetFileValue(file, key, value string) error {
+	cfg := Defaults()
+	f, err := lookupField(&cfg, key)
+	if err == nil {
+		err = f.set(value)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return fmt.Errorf("配置文件的顶层应为映射: %s", file)
+	}
+
+	node, err := newValueNode(f, value)
+	if err != nil {
+		return err
+	}
+
+	// 已有的单行的值直接在原文中替换，文件的其它内容（包括空行）保持不变
+	parts := strings.Split(key, ".")
+	if old := findNode(mapping, parts); old != nil {
+		if content, ok := replaceScalar(data, old, node); ok {
+			return writeConfigFile(file, data, content)
+		}
+	}
+
+	for i, part := range parts[:len(parts)-1] {
+		if mapping, err = childMapping(mapping, part); err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(parts[:i+1], "."), err)
+		}
+	}
+
+	setMappingValue(mapping, parts[len(parts)-1], node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return fmt.Errorf("生成配置文件失败: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("生成配置文件失败: %w", err)
+	}
+
+	return writeConfigFile(file, data, buf.Bytes())
+}
+
+// writeConfigFile 校验修改后的配置 content，修改引入了新的问题时返回 *ValidationError，文件保持不变
+// 修改前 old 已有的问题（如新文件还没有设置 flow.todo_dir）不影响写入，以便逐项修改配置
+func writeConfigFile(file string, old, content []byte) error {
+	if problems := newProblems(checkProblems(file, old), checkProblems(file, content)); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	if err := fileutil.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// checkProblems 返回配置文件内容为 data 时的问题，无法解析时作为一个问题
+func checkProblems(file string, data []byte) []Problem {
+	err := Check(file, data)
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Problems
+	}
+	return []Problem{{Source: SourceFile, Location: file, Message: err.Error()}}
+}
+
+// newProblems 返回 after 中不在 before 中的问题，修改后行号可能变化，只比较配置项和原因
+func newProblems(before, after []Problem) []Problem {
+	existing := map[[2]string]bool{}
+	for _, p := range before {
+		existing[[2]string{p.Key, p.Message}] = true
+	}
+
+	var problems []Problem
+	for _, p := range after {
+		if !existing[[2]string{p.Key, p.Message}] {
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+// findNode 按路径查找映射中的值节点，不存在时返回 nil
+func findNode(mapping *yaml.Node, path []string) *yaml.Node {
+	for _, name := range path {
+		if mapping == nil || mapping.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == name {
+				next = mapping.Content[i+1]
+				break
+			}
+		}
+		mapping = next
+	}
+	return mapping
+}
+
+// replaceScalar 在原文中把单行的标量 old 替换为 node，无法替换时返回 false
+func replaceScalar(data []byte, old, node *yaml.Node) ([]byte, bool) {
+	if old.Kind != yaml.ScalarNode || node.Kind != yaml.ScalarNode || old.Line <= 0 ||
+		old.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, false
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if old.Line > len(lines) {
+		return nil, false
+	}
+	line := []rune(lines[old.Line-1])
+	if old.Column-1 > len(line) {
+		return nil, false
+	}
+
+	// 值后面是行尾注释或换行符
+	rest := string(line[old.Column-1:])
+	eol := rest[len(strings.TrimRight(rest, "\r\n")):]
+	token := strings.TrimRight(rest, " \t\r\n")
+	if old.LineComment != "" {
+		if !strings.HasSuffix(token, old.LineComment) {
+			return nil, false
+		}
+		token = strings.TrimRight(strings.TrimSuffix(token, old.LineComment), " \t")
+	}
+
+	// 确认替换的内容就是原来的值
+	var parsed string
+	if err := yaml.Unmarshal([]byte(token), &parsed); err != nil || parsed != old.Value {
+		return nil, false
+	}
+
+	if node.Tag == "!!str" {
+		// 保留原来的引号风格
+		node.Style = old.Style
+	}
+	value, err := yaml.Marshal(node)
+	if err != nil {
+		return nil, false
+	}
+
+	suffix := rest[len(token):]
+	suffix = suffix[:len(suffix)-len(eol)]
+	lines[old.Line-1] = string(line[:old.Column-1]) + strings.TrimSuffix(string(value), "\n") + suffix + eol
+	return []byte(strings.Join(lines, "")), true
+}
+
+// Lookup 返回配置项当前的值和来源
+func Lookup(key string) (Entry, error) {
+	for _, entry := range Entries() {
+		if entry.Key == key {
+			return entry, nil
+		}
+	}
+	cfg := Defaults()
+	_, err := lookupField(&cfg, key)
+	return Entry{}, fmt.Errorf("%s: %w", key, err)
+}
+
+// childMapping 返回映射中 name 对应的子映射，不存在时创建
+func childMapping(mapping *yaml.Node, name string) (*yaml.Node, error) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != name {
+			continue
+		}
+		value := mapping.Content[i+1]
+		switch {
+		case value.Kind == yaml.MappingNode:
+			return value, nil
+		case value.Kind == yaml.ScalarNode && value.Tag == "!!null":
+			*value = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: value.LineComment}
+			return value, nil
+		}
+		return nil, fmt.Errorf("应为映射")
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, value)
+	return value, nil
+}
+
+// setMappingValue 设置映射中 name 的值，替换已有的值时保留原来的注释
+func setMappingValue(mapping *yaml.Node, name string, node *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != name {
+			continue
+		}
+		old := mapping.Content[i+1]
+		node.HeadComment, node.LineComment, node.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		if node.Kind == yaml.ScalarNode && old.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+			// 保留原来的引号风格
+			node.Style = old.Style
+		}
+		mapping.Content[i+1] = node
+		return
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, node)
+}
+
+// newValueNode 创建配置项的值节点，字符串直接使用，其它类型按 YAML 解析
+func newValueNode(f field, value string) (*yaml.Node, error) {
+	if f.value.Kind() == reflect.String {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%s: 值 %q 无效，应为%s", f.key, value, describeType(f.value.Type()))
+	}
+	return doc.Content[0], nil
+}
+
+// quoteYAML 返回 YAML 格式的字符串，需要时加引号
+func quoteYAML(s string) string {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
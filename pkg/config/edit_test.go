@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderInit(t *testing.T) {
+	defer func() { GlobalConfig = Config{} }()
+
+	dir := filepath.Join(t.TempDir(), "my todo")
+	require.NoError(t, os.Mkdir(dir, 0755))
+
+	content, err := RenderInit(InitOptions{TodoDir: dir, WeekStart: "sunday", CurrentYear: 2024})
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# todo 文件夹路径")
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, content, 0644))
+	require.NoError(t, Load(file, nil))
+
+	cfg := Get()
+	assert.Equal(t, dir, cfg.Flow.TodoDir)
+	assert.Equal(t, "sunday", cfg.Flow.WeekStart)
+	assert.Equal(t, 2024, cfg.Flow.CurrentYear)
+	assert.Equal(t, 10, cfg.Flow.Backup.Keep)
+}
+
+func TestSetFileValue(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	todoDir, otherDir := filepath.Join(dir, "todo"), filepath.Join(dir, "my todo # x")
+	require.NoError(t, os.Mkdir(todoDir, 0755))
+	require.NoError(t, os.Mkdir(otherDir, 0755))
+	original := "# 基础配置\n" +
+		"base:\n" +
+		"  config_path: \"./configs\"\n" +
+		"\n" +
+		"# flow 相关配置\n" +
+		"flow:\n" +
+		"  todo_dir: " + todoDir + " # todo 文件夹路径\n" +
+		"  backup: # 备份\n" +
+		"    keep: 10 # 保留的数量\n"
+	require.NoError(t, os.WriteFile(file, []byte(original), 0644))
+
+	read := func() string {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	// 已有的值直接替换，保留注释、空行和引号风格
+	require.NoError(t, SetFileValue(file, "flow.backup.keep", "5"))
+	require.NoError(t, SetFileValue(file, "base.config_path", "./other dir"))
+	require.NoError(t, SetFileValue(file, "flow.todo_dir", otherDir))
+	assert.Equal(t, "# 基础配置\n"+
+		"base:\n"+
+		"  config_path: \"./other dir\"\n"+
+		"\n"+
+		"# flow 相关配置\n"+
+		"flow:\n"+
+		"  todo_dir: '"+otherDir+"' # todo 文件夹路径\n"+
+		"  backup: # 备份\n"+
+		"    keep: 5 # 保留的数量\n", read())
+
+	// 新的配置项添加到对应的映射下
+	require.NoError(t, SetFileValue(file, "flow.backup.max_days", "30"))
+	require.NoError(t, SetFileValue(file, "flow.projects", "{work: {BUGFIX: [BCS, DUAL]}}"))
+	content := read()
+	assert.Contains(t, content, "  todo_dir: '"+otherDir+"' # todo 文件夹路径\n")
+	assert.Contains(t, content, "    keep: 5 # 保留的数量\n    max_days: 30\n")
+	assert.Contains(t, content, "  projects: {work: {BUGFIX: [BCS, DUAL]}}\n")
+
+	// 值无效时不修改文件
+	assert.ErrorContains(t, SetFileValue(file, "flow.backup.keep", "many"), "整数")
+	assert.ErrorContains(t, SetFileValue(file, "flow.bakup.keep", "1"), "flow.backup.keep")
+	assert.Equal(t, content, read())
+
+	// 修改后的配置校验失败时不修改文件
+	var validationErr *ValidationError
+	require.ErrorAs(t, SetFileValue(file, "flow.week_start", "funday"), &validationErr)
+	assert.Equal(t, "flow.week_start", validationErr.Problems[0].Key)
+	require.ErrorAs(t, SetFileValue(file, "flow.todo_dir", filepath.Join(dir, "missing")), &validationErr)
+	assert.Equal(t, "flow.todo_dir", validationErr.Problems[0].Key)
+	assert.Equal(t, content, read())
+}
+
+func TestSetFileValueNewFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, SetFileValue(file, "flow.backup.keep", "3"))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "flow:\n  backup:\n    keep: 3\n", string(content))
+
+	// 新文件还没有设置 flow.todo_dir，已有的问题不影响逐项修改，新的问题仍然拒绝
+	assert.Error(t, SetFileValue(file, "flow.week_start", "funday"))
+	require.NoError(t, SetFileValue(file, "flow.week_start", "sunday"))
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source 配置项的来源
+type Source string
+
+// 配置按以下顺序加载，后面的覆盖前面的
+const (
+	SourceDefault Source = "default" // 默认值
+	SourceFile    Source = "file"    // 配置文件
+	SourceEnv     Source = "env"     // 环境变量，如 MYCMD_FLOW_TODO_DIR
+	SourceFlag    Source = "flag"    // 命令行参数 --set flow.todo_dir=...
+)
+
+// EnvPrefix 配置项环境变量的前缀
+const EnvPrefix = "MYCMD_"
+
+// Entry 配置项的值和来源
+type Entry struct {
+	Key    string // 配置项，如 flow.todo_dir
+	Value  string // 配置项的值，列表和映射格式化为 JSON
+	Source Source // 配置项的来源
+	Env    string // 对应的环境变量
+}
+
+var (
+	// configFile 加载的配置文件路径
+	configFile string
+	// sources 每个配置项的来源，没有记录的配置项来自默认值
+	sources = map[string]Source{}
+)
+
+// Defaults 返回配置的默认值
+func Defaults() Config {
+	var cfg Config
+	cfg.Base.ConfigPath = "./configs"
+	cfg.Flow.WeekStart = "monday"
+	cfg.Flow.Backup.Keep = 10
+	return cfg
+}
+
+// Load 依次使用默认值、配置文件、环境变量和 --set 参数加载配置，并校验配置
+// file 为空时不读取配置文件；overrides 的格式为 key=value，如 flow.todo_dir=/tmp/todo
+// 配置有问题时返回 *ValidationError，包含所有的问题以及问题所在的位置
+func Load(file string, overrides []string) error {
+	var data []byte
+	if file != "" {
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return err
+		}
+	}
+
+	cfg, src, err := build(file, data, overrides)
+	if err != nil {
+		return err
+	}
+
+	GlobalConfig = cfg
+	configFile = file
+	sources = src
+
+	// 打印配置信息
+	printConfig()
+
+	return nil
+}
+
+// Check 校验配置文件 file 的内容为 data 时加载的配置，不修改当前配置
+// 配置有问题时返回 *ValidationError
+func Check(file string, data []byte) error {
+	_, _, err := build(file, data, nil)
+	return err
+}
+
+// build 按 Load 的顺序合并各层配置并校验，返回配置以及每个配置项的来源
+// file 为空时没有配置文件，data 为配置文件的内容
+func build(file string, data []byte, overrides []string) (Config, map[string]Source, error) {
+	cfg := Defaults()
+	src := map[string]Source{}
+	d := &decoder{file: file, lines: map[string]int{}}
+
+	if file != "" {
+		if err := d.decodeFile(data, &cfg); err != nil {
+			return Config{}, nil, err
+		}
+		for key := range d.lines {
+			src[key] = SourceFile
+		}
+	}
+	problems := d.problems
+
+	for _, f := range fields(&cfg) {
+		env := EnvName(f.key)
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		if err := f.set(value); err != nil {
+			problems = append(problems, Problem{Key: f.key, Source: SourceEnv, Location: env, Message: err.Error()})
+			continue
+		}
+		src[f.key] = SourceEnv
+	}
+
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			problems = append(problems, Problem{Key: key, Source: SourceFlag, Location: "--set", Message: "格式错误，应为 key=value"})
+			continue
+		}
+		f, err := lookupField(&cfg, key)
+		if err == nil {
+			err = f.set(value)
+		}
+		if err != nil {
+			problems = append(problems, Problem{Key: key, Source: SourceFlag, Location: "--set", Message: err.Error()})
+			continue
+		}
+		src[f.key] = SourceFlag
+	}
+
+	problems = append(problems, validate(&cfg, func(key string) (Source, string) {
+		switch src[key] {
+		case SourceFile:
+			return SourceFile, fmt.Sprintf("%s:%d", file, d.lines[key])
+		case SourceEnv:
+			return SourceEnv, EnvName(key)
+		case SourceFlag:
+			return SourceFlag, "--set"
+		}
+		return SourceDefault, ""
+	})...)
+	if len(problems) > 0 {
+		return Config{}, nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, src, nil
+}
+
+// File 返回加载的配置文件路径
+func File() string {
+	return configFile
+}
+
+// Keys 返回所有配置项
+func Keys() []string {
+	cfg := Defaults()
+	var keys []string
+	for _, f := range fields(&cfg) {
+		keys = append(keys, f.key)
+	}
+	return keys
+}
+
+// Entries 返回所有配置项当前的值和来源
+func Entries() []Entry {
+	cfg := GlobalConfig
+	var entries []Entry
+	for _, f := range fields(&cfg) {
+		source, ok := sources[f.key]
+		if !ok {
+			source = SourceDefault
+		}
+		entries = append(entries, Entry{
+			Key:    f.key,
+			Value:  formatValue(f.value),
+			Source: source,
+			Env:    EnvName(f.key),
+		})
+	}
+	return entries
+}
+
+// EnvName 返回配置项对应的环境变量，如 flow.todo_dir 对应 MYCMD_FLOW_TODO_DIR
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// field 配置项对应的结构体字段
+type field struct {
+	key   string
+	value reflect.Value
+}
+
+// fields 按 yaml 标签展开配置结构体，返回所有配置项，嵌套的结构体用 . 连接
+func fields(cfg *Config) []field {
+	return structFields(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func structFields(v reflect.Value, prefix string) []field {
+	var result []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		if v.Field(i).Kind() == reflect.Struct {
+			result = append(result, structFields(v.Field(i), key)...)
+			continue
+		}
+		result = append(result, field{key: key, value: v.Field(i)})
+	}
+	return result
+}
+
+// lookupField 按名称查找配置项
+func lookupField(cfg *Config, key string) (field, error) {
+	for _, f := range fields(cfg) {
+		if f.key == key {
+			return f, nil
+		}
+	}
+	if similar := suggest(key, Keys()); similar != "" {
+		return field{}, fmt.Errorf("未知的配置项，是否为 %s?", similar)
+	}
+	return field{}, fmt.Errorf("未知的配置项，可用的配置项: %s", strings.Join(Keys(), ", "))
+}
+
+// set 设置配置项的值，字符串直接使用，其它类型按 YAML 解析，如 10、[a, b]、{work: {BUGFIX: [BCS]}}
+func (f field) set(value string) error {
+	if f.value.Kind() == reflect.String {
+		f.value.SetString(value)
+		return nil
+	}
+
+	ptr := reflect.New(f.value.Type())
+	if err := yaml.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+		return fmt.Errorf("值 %q 无效，应为%s", value, describeType(f.value.Type()))
+	}
+	f.value.Set(ptr.Elem())
+	return nil
+}
+
+// formatValue 格式化配置项的值，列表和映射格式化为 JSON
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		if v.Len() == 0 {
+			return ""
+		}
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return string(data)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	defer func() { GlobalConfig = Config{} }()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`flow:
+  todo_dir: `+dir+`
+  current_year: 2023
+  week_start: sunday
+  backup:
+    keep: 5
+`), 0644))
+
+	t.Setenv("MYCMD_FLOW_CURRENT_YEAR", "2024")
+	t.Setenv("MYCMD_FLOW_BACKUP_MAX_DAYS", "30")
+	t.Setenv("MYCMD_FLOW_PROJECTS", "{work: {BUGFIX: [BCS, DUAL]}}")
+
+	require.NoError(t, Load(file, []string{"flow.week_start=friday", "flow.current_year=2025"}))
+
+	cfg := Get()
+	assert.Equal(t, "./configs", cfg.Base.ConfigPath)
+	assert.Equal(t, dir, cfg.Flow.TodoDir)
+	assert.Equal(t, 2025, cfg.Flow.CurrentYear)
+	assert.Equal(t, "friday", cfg.Flow.WeekStart)
+	assert.Equal(t, 5, cfg.Flow.Backup.Keep)
+	assert.Equal(t, 30, cfg.Flow.Backup.MaxDays)
+	assert.Equal(t, []string{"BCS", "DUAL"}, cfg.Flow.Projects["work"]["BUGFIX"])
+	assert.Equal(t, file, File())
+
+	sources := map[string]Source{}
+	for _, entry := range Entries() {
+		sources[entry.Key] = entry.Source
+	}
+	assert.Equal(t, map[string]Source{
+		"base.config_path":       SourceDefault,
+		"flow.todo_dir":          SourceFile,
+		"flow.current_year":      SourceFlag,
+		"flow.week_start":        SourceFlag,
+		"flow.archive_template":  SourceDefault,
+		"flow.projects":          SourceEnv,
+		"flow.symbols":           SourceDefault,
+		"flow.tags":              SourceDefault,
+		"flow.warn_unknown_tags": SourceDefault,
+		"flow.backup.keep":       SourceFile,
+		"flow.backup.max_days":   SourceEnv,
+	}, sources)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "MYCMD_FLOW_TODO_DIR", EnvName("flow.todo_dir"))
+	assert.Equal(t, "MYCMD_FLOW_BACKUP_MAX_DAYS", EnvName("flow.backup.max_days"))
+}
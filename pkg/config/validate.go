@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"mycmd/internal/flow/models"
+)
+
+// Problem 配置中的一个问题
+type Problem struct {
+	Key      string // 配置项，如 flow.todo_dir
+	Source   Source // 出问题的值的来源
+	Location string // 值所在的位置，如 config.yaml:3、MYCMD_FLOW_TODO_DIR、--set
+	Message  string
+}
+
+func (p Problem) String() string {
+	if p.Location == "" {
+		return fmt.Sprintf("%s: %s", p.Key, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Location, p.Key, p.Message)
+}
+
+// ValidationError 配置校验失败，包含所有的问题
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("配置有 %d 个问题:", len(e.Problems)))
+	for _, p := range e.Problems {
+		lines = append(lines, "  "+p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// weekdays flow.week_start 可用的值
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// statusNames flow.symbols 可用的状态
+var statusNames = []string{"in_progress", "done", "cancelled"}
+
+// decoder 严格解析配置文件，记录每个配置项所在的行号
+type decoder struct {
+	file     string
+	lines    map[string]int
+	problems []Problem
+}
+
+// decodeFile 解析配置文件到 cfg，未知的配置项和类型错误的值记录为问题，返回文件中设置了的配置项
+func (d *decoder) decodeFile(data []byte, cfg *Config) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if root.Kind == 0 || len(root.Content) == 0 {
+		// 空文件
+		return nil
+	}
+	d.decodeStruct(root.Content[0], reflect.ValueOf(cfg).Elem(), "")
+	return nil
+}
+
+func (d *decoder) decodeStruct(node *yaml.Node, v reflect.Value, prefix string) {
+	if node.Kind != yaml.MappingNode {
+		if node.Tag == "!!null" {
+			return
+		}
+		d.addProblem(prefix, node.Line, "应为映射")
+		return
+	}
+
+	fieldsByName := map[string]reflect.Value{}
+	var names []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldsByName[name] = v.Field(i)
+		names = append(names, name)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := joinKey(prefix, keyNode.Value)
+
+		fv, ok := fieldsByName[keyNode.Value]
+		if !ok {
+			message := "未知的配置项"
+			if similar := suggest(keyNode.Value, names); similar != "" {
+				message += fmt.Sprintf("，是否为 %s?", joinKey(prefix, similar))
+			}
+			d.addProblem(key, keyNode.Line, message)
+			continue
+		}
+
+		if fv.Kind() == reflect.Struct {
+			d.decodeStruct(valueNode, fv, key)
+			continue
+		}
+
+		d.lines[key] = keyNode.Line
+		ptr := reflect.New(fv.Type())
+		if err := valueNode.Decode(ptr.Interface()); err != nil {
+			d.addProblem(key, valueNode.Line, fmt.Sprintf("值 %q 无效，应为%s", valueNode.Value, describeType(fv.Type())))
+			continue
+		}
+		fv.Set(ptr.Elem())
+	}
+}
+
+func (d *decoder) addProblem(key string, line int, message string) {
+	d.problems = append(d.problems, Problem{
+		Key:      key,
+		Source:   SourceFile,
+		Location: fmt.Sprintf("%s:%d", d.file, line),
+		Message:  message,
+	})
+}
+
+// validate 校验配置的值，location 返回配置项的值所在的位置
+func validate(cfg *Config, source func(key string) (Source, string)) []Problem {
+	var problems []Problem
+	add := func(key, message string) {
+		src, location := source(key)
+		problems = append(problems, Problem{Key: key, Source: src, Location: location, Message: message})
+	}
+
+	flow := cfg.Flow
+	if flow.TodoDir == "" {
+		add("flow.todo_dir", fmt.Sprintf("必须设置，请在配置文件中设置或使用环境变量 %s", EnvName("flow.todo_dir")))
+	} else if info, err := os.Stat(flow.TodoDir); err != nil {
+		add("flow.todo_dir", fmt.Sprintf("文件夹 %s 不存在", flow.TodoDir))
+	} else if !info.IsDir() {
+		add("flow.todo_dir", fmt.Sprintf("%s 不是文件夹", flow.TodoDir))
+	}
+
+	if flow.CurrentYear != 0 && (flow.CurrentYear < 1000 || flow.CurrentYear > 9999) {
+		add("flow.current_year", fmt.Sprintf("年份 %d 无效，应为四位数的年份或 0（使用今年）", flow.CurrentYear))
+	}
+
+	if flow.WeekStart != "" && !contains(weekdays, strings.ToLower(strings.TrimSpace(flow.WeekStart))) {
+		add("flow.week_start", fmt.Sprintf("%q 无效，可用的值: %s", flow.WeekStart, strings.Join(weekdays, ", ")))
+	}
+
+	if path := flow.ArchiveTemplate; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(flow.TodoDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			add("flow.archive_template", fmt.Sprintf("模板文件 %s 不存在", path))
+		}
+	}
+
+	for _, status := range sortedKeys(flow.Symbols) {
+		if _, ok := models.StatusNames[status]; !ok {
+			add("flow.symbols", fmt.Sprintf("状态 %q 无效，可用的状态: %s", status, strings.Join(statusNames, ", ")))
+		}
+	}
+
+	// 符号和标签与 models.Configure 使用同样的规则检查，避免配置通过校验后在初始化时才失败
+	symbolErrs, tagErrs := cfg.Vocabulary().Check()
+	for _, err := range symbolErrs {
+		add("flow.symbols", err.Error())
+	}
+	for _, err := range tagErrs {
+		add("flow.tags", err.Error())
+	}
+
+	if flow.Backup.Keep < 0 {
+		add("flow.backup.keep", "不能小于 0")
+	}
+	if flow.Backup.MaxDays < 0 {
+		add("flow.backup.max_days", "不能小于 0")
+	}
+
+	return problems
+}
+
+// describeType 返回类型的中文描述，用于错误信息
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "整数"
+	case reflect.Bool:
+		return "布尔值 (true/false)"
+	case reflect.String:
+		return "字符串"
+	case reflect.Slice:
+		return "列表"
+	case reflect.Map:
+		return "映射"
+	}
+	return t.String()
+}
+
+// suggest 返回与 name 最相似的名称，都不相似时返回空串
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", len(name)/2+1
+	for _, candidate := range candidates {
+		if d := levenshtein(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// levenshtein 计算两个字符串的编辑距离
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
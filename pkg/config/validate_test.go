@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadValidation(t *testing.T) {
+	defer func() { GlobalConfig = Config{} }()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`flow:
+  todo_dirr: /tmp
+  current_year: abc
+  week_start: mon
+  archive_template: missing.tmpl
+  backup:
+    kep: 3
+unknown: 1
+`), 0644))
+
+	t.Setenv("MYCMD_FLOW_BACKUP_MAX_DAYS", "-1")
+
+	err := Load(file, []string{"flow.backup.keep=many", "flow.todo_dri=/tmp", "flow.week_start"})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	var problems []string
+	for _, p := range validationErr.Problems {
+		problems = append(problems, p.String())
+	}
+	assert.Equal(t, []string{
+		file + ":2: flow.todo_dirr: 未知的配置项，是否为 flow.todo_dir?",
+		file + `:3: flow.current_year: 值 "abc" 无效，应为整数`,
+		file + ":7: flow.backup.kep: 未知的配置项，是否为 flow.backup.keep?",
+		file + ":8: unknown: 未知的配置项",
+		`--set: flow.backup.keep: 值 "many" 无效，应为整数`,
+		"--set: flow.todo_dri: 未知的配置项，是否为 flow.todo_dir?",
+		"--set: flow.week_start: 格式错误，应为 key=value",
+		"flow.todo_dir: 必须设置，请在配置文件中设置或使用环境变量 MYCMD_FLOW_TODO_DIR",
+		file + `:4: flow.week_start: "mon" 无效，可用的值: sunday, monday, tuesday, wednesday, thursday, friday, saturday`,
+		file + ":5: flow.archive_template: 模板文件 missing.tmpl 不存在",
+		"MYCMD_FLOW_BACKUP_MAX_DAYS: flow.backup.max_days: 不能小于 0",
+	}, problems)
+
+	// 校验失败时不修改当前配置
+	assert.Empty(t, Get().Flow.TodoDir)
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "weekly.tmpl"), nil, 0644))
+	location := func(key string) (Source, string) { return SourceDefault, "" }
+
+	cfg := Defaults()
+	cfg.Flow.TodoDir = dir
+	cfg.Flow.CurrentYear = 2024
+	cfg.Flow.WeekStart = "Sunday"
+	cfg.Flow.ArchiveTemplate = "weekly.tmpl"
+	assert.Empty(t, validate(&cfg, location))
+
+	cfg.Flow.TodoDir = file
+	cfg.Flow.CurrentYear = 24
+	problems := validate(&cfg, location)
+	require.Len(t, problems, 3)
+	assert.Equal(t, "flow.todo_dir", problems[0].Key)
+	assert.Equal(t, "flow.current_year", problems[1].Key)
+	assert.Equal(t, "flow.archive_template", problems[2].Key)
+}
+
+func TestValidateSymbolsAndTags(t *testing.T) {
+	location := func(key string) (Source, string) { return SourceDefault, "" }
+
+	cfg := Defaults()
+	cfg.Flow.TodoDir = t.TempDir()
+	cfg.Flow.Symbols = map[string]StatusSymbols{
+		"in_progress": {Extra: []string{"◻"}},
+		"done":        {Preferred: "✅"},
+	}
+	cfg.Flow.Tags = []string{"review", "@blocked"}
+	assert.Empty(t, validate(&cfg, location))
+
+	cfg.Flow.Symbols = map[string]StatusSymbols{
+		"todo":      {Extra: []string{"◻"}},
+		"done":      {Preferred: "✅ ok", Extra: []string{""}},
+		"cancelled": {Preferred: "✔"},
+	}
+	cfg.Flow.Tags = []string{"@", "due(1)", "re.view", "a/b"}
+
+	var problems []string
+	for _, p := range validate(&cfg, location) {
+		problems = append(problems, p.String())
+	}
+	assert.Equal(t, []string{
+		`flow.symbols: 状态 "todo" 无效，可用的状态: in_progress, done, cancelled`,
+		"flow.symbols: 已完成的符号不能为空",
+		`flow.symbols: 已取消的符号 "✔" 已用于状态 已完成`,
+		`flow.symbols: 已完成的符号 "✅ ok" 不能包含空白或 @`,
+		"flow.tags: 标签名称不能为空",
+		`flow.tags: 标签名称 "@due(1)" 无效，只能包含字母、数字、_ 和 -`,
+		`flow.tags: 标签名称 "@re.view" 无效，只能包含字母、数字、_ 和 -`,
+		`flow.tags: 标签名称 "@a/b" 无效，只能包含字母、数字、_ 和 -`,
+	}, problems)
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"todo_dir", "current_year", "week_start"}
+	assert.Equal(t, "todo_dir", suggest("todo_dirr", names))
+	assert.Equal(t, "week_start", suggest("weekstart", names))
+	assert.Equal(t, "", suggest("backup", names))
+}
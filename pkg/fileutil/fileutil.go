@@ -0,0 +1,63 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile 原子地写入文件：先写入同目录下的临时文件并 fsync，再重命名为目标文件
+// 写入过程中出错或被中断时，目标文件保持原来的内容，不会出现只写了一半的文件
+// 目标文件已存在时保留其权限；目标文件是符号链接时写入链接指向的文件
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	target, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(target)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(target)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	// 重命名成功后临时文件已不存在，删除失败可以忽略
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("设置文件权限失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("同步临时文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("关闭临时文件失败: %w", err)
+	}
+
+	if err := os.Rename(tmpName, target); err != nil {
+		return fmt.Errorf("重命名临时文件失败: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// resolvePath 解析符号链接，避免重命名时用普通文件替换掉符号链接
+func resolvePath(path string) (string, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if os.IsNotExist(err) {
+		return path, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("解析文件路径失败: %w", err)
+	}
+	return target, nil
+}
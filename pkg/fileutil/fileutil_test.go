@@ -0,0 +1,57 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "work.todo")
+
+	// 文件不存在时创建
+	require.NoError(t, WriteFile(path, []byte("v1"), 0644))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+
+	// 覆盖时保留原文件的权限
+	require.NoError(t, os.Chmod(path, 0600))
+	require.NoError(t, WriteFile(path, []byte("v2"), 0644))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// 不留下临时文件
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFileSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.todo")
+	link := filepath.Join(dir, "work.todo")
+	require.NoError(t, os.WriteFile(target, []byte("v1"), 0644))
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("不支持符号链接: %v", err)
+	}
+
+	require.NoError(t, WriteFile(link, []byte("v2"), 0644))
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+}
@@ -0,0 +1,100 @@
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LockTimeout 等待其它进程释放锁的最长时间
+var LockTimeout = 10 * time.Second
+
+// lockRetryInterval 获取锁失败后重试的间隔
+const lockRetryInterval = 50 * time.Millisecond
+
+// errLocked 锁被其它进程持有
+var errLocked = errors.New("file is locked")
+
+// Lock 文件的建议锁 (advisory lock)
+// 锁加在文件旁边的 .<文件名>.lock 文件上，而不是文件本身：
+// WriteFile 通过重命名替换文件，加在原文件上的锁在重命名后就不再对应新文件
+type Lock struct {
+	path  string
+	file  *os.File
+	count int
+}
+
+var (
+	locksMu sync.Mutex
+	locks   = map[string]*Lock{}
+)
+
+// LockFile 获取文件的排他锁，锁被其它进程持有时最多等待 LockTimeout
+// 同一进程内可以重复获取同一个文件的锁，每次 LockFile 都需要对应一次 Unlock
+func LockFile(path string) (*Lock, error) {
+	target, err := resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	lockPath, err := filepath.Abs(filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+".lock"))
+	if err != nil {
+		return nil, err
+	}
+
+	locksMu.Lock()
+	defer locksMu.Unlock()
+
+	if l, ok := locks[lockPath]; ok {
+		l.count++
+		return l, nil
+	}
+
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("创建锁文件失败: %w", err)
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		err = tryLock(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) || time.Now().After(deadline) {
+			f.Close()
+			if errors.Is(err, errLocked) {
+				return nil, fmt.Errorf("文件 %s 正在被其它进程修改，请稍后重试", path)
+			}
+			return nil, fmt.Errorf("获取文件锁失败: %w", err)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	l := &Lock{path: lockPath, file: f, count: 1}
+	locks[lockPath] = l
+	return l, nil
+}
+
+// Unlock 释放文件锁
+func (l *Lock) Unlock() error {
+	locksMu.Lock()
+	defer locksMu.Unlock()
+
+	l.count--
+	if l.count > 0 {
+		return nil
+	}
+	delete(locks, l.path)
+
+	err := unlock(l.file)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("释放文件锁失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "work.todo")
+	lockPath := filepath.Join(dir, ".work.todo.lock")
+
+	l, err := LockFile(path)
+	require.NoError(t, err)
+
+	// 同一进程内可以重复加锁
+	l2, err := LockFile(path)
+	require.NoError(t, err)
+	assert.Same(t, l, l2)
+
+	// 模拟其它进程：新打开的文件无法获取锁
+	f, err := os.Open(lockPath)
+	require.NoError(t, err)
+	defer f.Close()
+	assert.ErrorIs(t, tryLock(f), errLocked)
+
+	// 第一次释放后仍然持有锁
+	require.NoError(t, l2.Unlock())
+	assert.ErrorIs(t, tryLock(f), errLocked)
+
+	require.NoError(t, l.Unlock())
+	require.NoError(t, tryLock(f))
+	require.NoError(t, unlock(f))
+}
+
+func TestLockFileTimeout(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "work.todo")
+
+	// 模拟其它进程持有锁
+	f, err := os.OpenFile(filepath.Join(dir, ".work.todo.lock"), os.O_RDWR|os.O_CREATE, 0644)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, tryLock(f))
+
+	timeout := LockTimeout
+	LockTimeout = 100 * time.Millisecond
+	defer func() { LockTimeout = timeout }()
+
+	_, err = LockFile(path)
+	assert.ErrorContains(t, err, "正在被其它进程修改")
+
+	// 其它进程释放锁后可以获取
+	require.NoError(t, unlock(f))
+	l, err := LockFile(path)
+	require.NoError(t, err)
+	require.NoError(t, l.Unlock())
+}
@@ -0,0 +1,20 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package fileutil
+
+import "os"
+
+// 不支持 flock 的系统上不加锁
+
+func tryLock(f *os.File) error {
+	return nil
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
+
+// syncDir 不支持同步目录的系统上什么都不做
+func syncDir(dir string) error {
+	return nil
+}
@@ -1,39 +1,53 @@
 package initialize
 
 import (
-	"flag"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"mycmd/internal/flow/models"
 	"mycmd/pkg/config"
 )
 
-var (
-	configFile string
-)
+const (
+	// ConfigEnv 指定配置文件路径的环境变量
+	ConfigEnv = "MYCMD_CONFIG"
 
-// Init 初始化所有模块
-func Init() error {
-	// 解析命令行参数
-	parseFlags()
+	// defaultConfigFile 默认的配置文件名
+	defaultConfigFile = "config.yaml"
+)
 
+// Init 初始化所有模块，configFile 为空时按 MYCMD_CONFIG、./config.yaml、~/.config/mycmd/config.yaml 的顺序查找配置文件
+// overrides 为 --set 指定的配置项，优先于配置文件和环境变量
+func Init(configFile string, overrides []string) error {
 	// 初始化配置
-	if err := initConfig(); err != nil {
+	if err := initConfig(configFile, overrides); err != nil {
 		return fmt.Errorf("初始化配置失败: %w", err)
 	}
 
+	// 合并配置中的状态符号和标签
+	if err := initVocabulary(config.GlobalConfig); err != nil {
+		return fmt.Errorf("初始化状态符号和标签失败: %w", err)
+	}
+
 	return nil
 }
 
-// parseFlags 解析命令行参数
-func parseFlags() {
-	flag.StringVar(&configFile, "config", "config.yaml", "配置文件路径")
-	flag.Parse()
+// initVocabulary 把配置 flow.symbols 和 flow.tags 合并到内置的符号和标签中
+// 加载配置时已经按同样的规则校验过，这里出错说明配置没有经过校验
+func initVocabulary(cfg config.Config) error {
+	return models.Configure(cfg.Vocabulary())
 }
 
 // initConfig 初始化配置
-func initConfig() error {
+func initConfig(configFile string, overrides []string) error {
+	configFile, err := ResolveConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+
 	// 获取配置文件的绝对路径
 	absPath, err := filepath.Abs(configFile)
 	if err != nil {
@@ -46,9 +60,57 @@ func initConfig() error {
 	}
 
 	// 加载配置文件
-	if err := config.LoadConfig(absPath); err != nil {
-		return fmt.Errorf("加载配置文件失败: %w", err)
+	if err := config.Load(absPath, overrides); err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
 	}
 
 	return nil
-} 
\ No newline at end of file
+}
+
+// ResolveConfigFile 返回配置文件路径
+// 优先使用 --config 指定的文件，其次是环境变量 MYCMD_CONFIG，都没有指定时依次查找当前目录和 XDG 配置目录
+func ResolveConfigFile(configFile string) (string, error) {
+	if configFile != "" {
+		return configFile, nil
+	}
+	if env := os.Getenv(ConfigEnv); env != "" {
+		return env, nil
+	}
+
+	candidates := []string{defaultConfigFile}
+	if dir, err := xdgConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "mycmd", defaultConfigFile))
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("没有找到配置文件，请使用 --config 或环境变量 %s 指定，已查找: %s",
+		ConfigEnv, strings.Join(candidates, ", "))
+}
+
+// DefaultConfigFile 返回 XDG 配置目录下的配置文件 ~/.config/mycmd/config.yaml，config init 默认写入该文件
+func DefaultConfigFile() (string, error) {
+	dir, err := xdgConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取配置目录失败: %w", err)
+	}
+	return filepath.Join(dir, "mycmd", defaultConfigFile), nil
+}
+
+// xdgConfigDir 返回 XDG 配置目录，优先使用 XDG_CONFIG_HOME，默认为 ~/.config
+func xdgConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if home == "" {
+		return "", errors.New("home directory is empty")
+	}
+	return filepath.Join(home, ".config"), nil
+}
@@ -0,0 +1,59 @@
+package initialize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	xdg := filepath.Join(dir, "xdg")
+	xdgConfig := filepath.Join(xdg, "mycmd", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(xdgConfig), 0755))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv(ConfigEnv, "")
+
+	// 没有找到配置文件
+	_, err = ResolveConfigFile("")
+	assert.ErrorContains(t, err, xdgConfig)
+
+	// XDG 配置目录
+	require.NoError(t, os.WriteFile(xdgConfig, nil, 0644))
+	file, err := ResolveConfigFile("")
+	require.NoError(t, err)
+	assert.Equal(t, xdgConfig, file)
+
+	// 当前目录优先于 XDG 配置目录
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), nil, 0644))
+	file, err = ResolveConfigFile("")
+	require.NoError(t, err)
+	assert.Equal(t, "config.yaml", file)
+
+	// 环境变量优先于查找
+	t.Setenv(ConfigEnv, "/etc/mycmd.yaml")
+	file, err = ResolveConfigFile("")
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/mycmd.yaml", file)
+
+	// --config 优先于环境变量
+	file, err = ResolveConfigFile("custom.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "custom.yaml", file)
+}
+
+func TestDefaultConfigFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	file, err := DefaultConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/xdg", "mycmd", "config.yaml"), file)
+}
@@ -1,35 +1,203 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 )
 
+// Level 日志级别
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// LevelNames 可用的日志级别
+var LevelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return LevelNames[l]
+}
+
+// ParseLevel 解析日志级别，支持 debug、info、warn (warning)、error
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return 0, fmt.Errorf("无效的日志级别: %s，可用的级别: %s", s, strings.Join(LevelNames, ", "))
+}
+
+// Style 输出的颜色
+type Style int
+
+const (
+	StylePlain Style = iota
+	StyleSuccess
+	StyleError
+	StyleWarning
+	StyleInfo
+	StyleDebug
+)
+
+var styleAttributes = map[Style][]color.Attribute{
+	StyleSuccess: {color.FgGreen, color.Bold},
+	StyleError:   {color.FgRed, color.Bold},
+	StyleWarning: {color.FgYellow, color.Bold},
+	StyleInfo:    {color.FgBlue, color.Bold},
+	StyleDebug:   {color.FgHiBlack},
+}
+
 var (
-	success = color.New(color.FgGreen, color.Bold).SprintfFunc()
-	error   = color.New(color.FgRed, color.Bold).SprintfFunc()
-	warning = color.New(color.FgYellow, color.Bold).SprintfFunc()
-	info    = color.New(color.FgBlue, color.Bold).SprintfFunc()
-	debug   = color.New(color.FgHiBlack).SprintfFunc()
+	mu sync.Mutex
+
+	// level 低于该级别的日志不输出
+	level = LevelInfo
+	// output 日志输出到标准错误，不影响标准输出中的命令结果
+	output io.Writer = os.Stderr
+	// logFile 以 JSON 格式额外写入日志的文件
+	logFile io.WriteCloser
 )
 
+// SetLevel 设置日志级别
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// GetLevel 返回当前的日志级别
+func GetLevel() Level {
+	mu.Lock()
+	defer mu.Unlock()
+	return level
+}
+
+// SetOutput 设置日志的输出，默认为标准错误
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	output = w
+}
+
+// SetLogFile 额外以 JSON 格式把日志追加写入文件，每行一条，path 为空时关闭日志文件
+func SetLogFile(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if logFile != nil {
+		logFile.Close()
+		logFile = nil
+	}
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	logFile = f
+	return nil
+}
+
+// Close 关闭日志文件
+func Close() error {
+	return SetLogFile("")
+}
+
 func Success(format string, a ...interface{}) {
-	fmt.Println(success(format, a...))
+	log(LevelInfo, StyleSuccess, format, a...)
 }
 
 func Error(format string, a ...interface{}) {
-	fmt.Println(error(format, a...))
+	log(LevelError, StyleError, format, a...)
 }
 
 func Warning(format string, a ...interface{}) {
-	fmt.Println(warning(format, a...))
+	log(LevelWarn, StyleWarning, format, a...)
 }
 
 func Info(format string, a ...interface{}) {
-	fmt.Println(info(format, a...))
+	log(LevelInfo, StyleInfo, format, a...)
 }
 
 func Debug(format string, a ...interface{}) {
-	fmt.Println(debug(format, a...))
-}
\ No newline at end of file
+	log(LevelDebug, StyleDebug, format, a...)
+}
+
+// Print 把命令的结果（如表格、diff）输出到标准输出，不受日志级别影响
+func Print(style Style, format string, a ...interface{}) {
+	Fprint(os.Stdout, style, format, a...)
+}
+
+// Fprint 把命令的结果输出到 w，w 是终端时使用颜色
+func Fprint(w io.Writer, style Style, format string, a ...interface{}) {
+	fmt.Fprintln(w, paint(w, style, fmt.Sprintf(format, a...)))
+}
+
+func log(l Level, style Style, format string, a ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if l < level {
+		return
+	}
+
+	msg := fmt.Sprintf(format, a...)
+	fmt.Fprintln(output, paint(output, style, msg))
+
+	if logFile != nil {
+		data, err := json.Marshal(struct {
+			Time  string `json:"time"`
+			Level string `json:"level"`
+			Msg   string `json:"msg"`
+		}{time.Now().Format(time.RFC3339Nano), l.String(), msg})
+		if err == nil {
+			logFile.Write(append(data, '\n'))
+		}
+	}
+}
+
+// paint w 是终端且没有设置 NO_COLOR 时给内容加上颜色
+func paint(w io.Writer, style Style, s string) string {
+	attrs, ok := styleAttributes[style]
+	if !ok || !colorEnabled(w) {
+		return s
+	}
+	c := color.New(attrs...)
+	c.EnableColor()
+	return c.Sprint(s)
+}
+
+// colorEnabled 判断输出到 w 时是否使用颜色，遵循 https://no-color.org
+func colorEnabled(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	for name, expected := range map[string]Level{
+		"debug":   LevelDebug,
+		"INFO":    LevelInfo,
+		"warn":    LevelWarn,
+		"warning": LevelWarn,
+		"error":   LevelError,
+	} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, level)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+	assert.Equal(t, "warn", LevelWarn.String())
+}
+
+func TestLevel(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+	defer SetLevel(LevelInfo)
+
+	SetLevel(LevelWarn)
+	Debug("debug")
+	Info("info")
+	Success("success")
+	Warning("warning %d", 1)
+	Error("error")
+	// 输出不是终端时不使用颜色
+	assert.Equal(t, "warning 1\nerror\n", buf.String())
+
+	buf.Reset()
+	SetLevel(LevelDebug)
+	Debug("debug")
+	Success("success")
+	assert.Equal(t, "debug\nsuccess\n", buf.String())
+}
+
+func TestLogFile(t *testing.T) {
+	SetOutput(&bytes.Buffer{})
+	defer SetOutput(os.Stderr)
+	defer SetLevel(LevelInfo)
+
+	path := filepath.Join(t.TempDir(), "mycmd.log")
+	require.NoError(t, SetLogFile(path))
+	SetLevel(LevelInfo)
+	Debug("忽略")
+	Success("已完成: %s", "a")
+	Error("失败")
+	require.NoError(t, Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry struct {
+		Time  string `json:"time"`
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "info", entry.Level)
+	assert.Equal(t, "已完成: a", entry.Msg)
+	assert.NotEmpty(t, entry.Time)
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "error", entry.Level)
+}
+
+func TestFprint(t *testing.T) {
+	var buf bytes.Buffer
+	Fprint(&buf, StyleSuccess, "%s|%d", "a", 1)
+	assert.Equal(t, "a|1\n", buf.String())
+}